@@ -66,12 +66,18 @@ func CreateSite(
 	}, nil
 }
 
+// DeleteDoc deletes the document with the record key rkey.
+func (s *Site) DeleteDoc(ctx context.Context, client xrpc.Client, rkey atproto.RecordKey) error {
+	return xrpc.DeleteRecord[*site.Document](ctx, client, rkey, nil, nil)
+}
+
 func (s *Site) PublishDoc(
 	ctx context.Context,
 	client xrpc.Client,
 	title string,
 	path string,
 	publishedAt time.Time,
+	updatedAt *time.Time,
 	description string,
 	imagePath *string,
 	tags []string,
@@ -98,6 +104,7 @@ func (s *Site) PublishDoc(
 		Site:         site.FromRawAT(s.URL),
 		Title:        title,
 		PublishedAt:  publishedAt,
+		UpdatedAt:    updatedAt,
 		Path:         &path,
 		Description:  &description,
 		Tags:         tags,
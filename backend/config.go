@@ -4,8 +4,12 @@ import (
 	"html/template"
 	"log/slog"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"anhgelus.world/small-web/backend/images"
 	"anhgelus.world/small-web/dom"
 	"anhgelus.world/small-web/markdown"
 	"anhgelus.world/xrpc/atproto"
@@ -37,8 +41,24 @@ type Logo struct {
 }
 
 type Replacer struct {
-	Symbol  string `toml:"symbol"`
-	Replace string `tomle:"replace"`
+	Symbol string `toml:"symbol"`
+	// Pattern is a regular expression used instead of Symbol if it is set
+	Pattern string `toml:"pattern"`
+	Replace string `toml:"replace"`
+}
+
+// Text contains the texts of the interface.
+type Text struct {
+	// Backlinks is the heading of the articles linking to an article.
+	Backlinks string `toml:"backlinks"`
+	// Tag is the description of the page of a tag, {tag} is replaced by its name.
+	Tag string `toml:"tag"`
+}
+
+// DefaultText is used for the texts missing in the config.
+var DefaultText = Text{
+	Backlinks: "Référencé par",
+	Tag:       "Articles avec le tag {tag}.",
 }
 
 type ATProto struct {
@@ -59,16 +79,33 @@ type Config struct {
 
 	DataFolder   string `toml:"data_folder"`
 	PublicFolder string `toml:"public_folder"`
+	CacheFolder  string `toml:"cache_folder"`
 
 	Logo Logo `toml:"logo"`
 
 	ATProto ATProto `toml:"atproto"`
 
+	// Sections are routed when the server starts.
+	// A section added while the config is watched is only served after a restart, a removed one is not found.
 	Sections []*Section `toml:"section"`
 
 	Links []Link `toml:"links"`
 
 	Replacers []Replacer `toml:"replacers"`
+
+	Text Text `toml:"text"`
+
+	// HTML is the allowlist of raw HTML elements in articles, with their attributes.
+	// markdown.DefaultHTML is used if it is not set.
+	HTML map[string][]string `toml:"html"`
+
+	images *images.Processor
+	// markdown is the Option used to parse the articles
+	markdown markdown.Option
+	// backlinks are the articles linking to each URI
+	backlinks map[string][]*Article
+	// loaded are the articles of the previous load by file path, only set during the load
+	loaded map[string]*Article
 }
 
 func (c *Config) DefaultValues() {
@@ -98,19 +135,25 @@ func (c *Config) DefaultValues() {
 	}}
 	c.DataFolder = "data"
 	c.PublicFolder = "public"
+	c.CacheFolder = "cache"
 	c.Database = "database.sqlite"
 	c.AdminPassword = "Ch@ngeM€Please!"
 	c.Quotes = []string{"Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do."}
-	c.Replacers = []Replacer{{"~", "&thinsp;"}}
+	c.Replacers = []Replacer{{Symbol: "~", Replace: "&thinsp;"}}
+	c.Text = DefaultText
 	c.ATProto.DID = "did:plc:1234"
 	c.ATProto.Password = "your password"
 	c.ATProto.PublicationRKey = "foobar"
 	c.ATProto.DisplayName = "foobar"
 }
 
-var defaultMarkdownOption markdown.Option
-
 func LoadConfig(p string) *Config {
+	return loadConfig(p, nil)
+}
+
+// loadConfig loads the config p.
+// If prev is not nil, its image processor and the cached data of its articles which were not modified are reused.
+func loadConfig(p string, prev *Config) *Config {
 	b, err := os.ReadFile(p)
 	var cfg Config
 	if err != nil {
@@ -142,26 +185,208 @@ func LoadConfig(p string) *Config {
 	if len(cfg.AdminPassword) == 0 {
 		cfg.AdminPassword = os.Getenv("SW_ADMIN_PASSWORD")
 	}
-	defaultMarkdownOption.ImageSource = func(path string) string {
-		if strings.HasPrefix(path, "https://") {
+	cfg.markdown.ImageSource = func(path string) string {
+		if markdown.ExternalLink.MatchString(path) {
 			return path
 		}
 		return "/static/" + strings.TrimPrefix(path, "/")
 	}
-	defaultMarkdownOption.Replaces = make(map[rune]string, len(cfg.Replacers))
+	if len(cfg.CacheFolder) == 0 {
+		cfg.CacheFolder = "cache"
+	}
+	if len(cfg.Text.Backlinks) == 0 {
+		cfg.Text.Backlinks = DefaultText.Backlinks
+	}
+	if len(cfg.Text.Tag) == 0 {
+		cfg.Text.Tag = DefaultText.Tag
+	}
+	if prev != nil && prev.PublicFolder == cfg.PublicFolder && prev.CacheFolder == cfg.CacheFolder {
+		cfg.images = prev.images
+	} else {
+		cfg.images, err = images.New(cfg.PublicFolder, cfg.CacheFolder, "/img/")
+		if err != nil {
+			slog.Error("creating image processor", "error", err)
+			return nil
+		}
+	}
+	cfg.markdown.ResponsiveImage = cfg.ResponsiveImage
+	cfg.markdown.ImageSizes = ImageSizes["content"]
+	cfg.markdown.Directives = directives
+	cfg.markdown.WikiLink = cfg.WikiLink
+	cfg.markdown.Typography = cfg.Language
+	cfg.markdown.HTML = cfg.HTML
+	if cfg.markdown.HTML == nil {
+		cfg.markdown.HTML = markdown.DefaultHTML
+	}
+	cfg.markdown.Replaces = make([]markdown.Replacer, 0, len(cfg.Replacers))
 	for _, r := range cfg.Replacers {
-		if len(r.Symbol) != 1 {
-			slog.Error("invalid symbol in config", "symbol", r.Symbol)
+		rep := markdown.Replacer{Symbol: r.Symbol, Replace: r.Replace}
+		if len(r.Pattern) > 0 {
+			rep.Pattern, err = regexp.Compile(r.Pattern)
+			if err != nil {
+				slog.Error("invalid pattern in config", "error", err, "pattern", r.Pattern)
+				return nil
+			}
+		} else if len(r.Symbol) == 0 {
+			slog.Error("empty symbol in config", "replace", r.Replace)
 			return nil
 		}
-		defaultMarkdownOption.Replaces[[]rune(r.Symbol)[0]] = r.Replace
+		cfg.markdown.Replaces = append(cfg.markdown.Replaces, rep)
+	}
+	if prev != nil {
+		cfg.loaded = make(map[string]*Article)
+		for _, sec := range prev.Sections {
+			for _, art := range sec.All() {
+				cfg.loaded[art.filePath] = art
+			}
+		}
 	}
 	for _, sec := range cfg.Sections {
+		sec.config = &cfg
 		err = sec.Init(sec.Folder)
 		if err != nil {
 			slog.Error("cannot load section", "error", err, "name", sec.Name)
 			return nil
 		}
 	}
+	cfg.checkWikiLinks()
+	cfg.indexBacklinks()
+	cfg.generateImages(prev == nil || prev.images != cfg.images)
+	cfg.loaded = nil
 	return &cfg
 }
+
+// Section returns the section named name, or nil if it does not exist.
+func (c *Config) Section(name string) *Section {
+	for _, sec := range c.Sections {
+		if sec.Name == name {
+			return sec
+		}
+	}
+	return nil
+}
+
+// Parse parses the article stored in filePath outside of a section.
+func (c *Config) Parse(filePath string) (*Article, error) {
+	art, err := Parse(filePath)
+	if err != nil {
+		return nil, err
+	}
+	art.config = c
+	return art, nil
+}
+
+// WikiLink returns the URI and the title of the article targeted by section/slug.
+// The section is identified by its name or by its URI.
+func (c *Config) WikiLink(target string) (string, string, bool) {
+	name, slug, ok := strings.Cut(target, "/")
+	if !ok {
+		return "", "", false
+	}
+	for _, sec := range c.Sections {
+		if sec.Name != name && sec.URI != name {
+			continue
+		}
+		if art := sec.Get(slug); art != nil {
+			return art.URI, art.Title, true
+		}
+	}
+	return "", "", false
+}
+
+// checkWikiLinks logs the dangling wiki links of every article.
+func (c *Config) checkWikiLinks() {
+	for _, sec := range c.Sections {
+		for _, art := range sec.All() {
+			for _, target := range art.WikiLinks() {
+				// the fragment is not checked
+				slug, _, _ := strings.Cut(target, "#")
+				if _, _, ok := art.wikiLink(slug); !ok {
+					slog.Error("dangling wiki link", "article", art.URI, "target", target)
+				}
+			}
+		}
+	}
+}
+
+// indexBacklinks indexes the articles linking to each URI.
+func (c *Config) indexBacklinks() {
+	c.backlinks = make(map[string][]*Article)
+	for _, sec := range c.Sections {
+		for _, a := range sec.All() {
+			for _, uri := range a.Links() {
+				if uri != a.URI && !slices.Contains(c.backlinks[uri], a) {
+					c.backlinks[uri] = append(c.backlinks[uri], a)
+				}
+			}
+		}
+	}
+}
+
+// Backlinks returns the listed articles linking to art.
+// Links are indexed when the config is loaded.
+func (c *Config) Backlinks(art *Article) []*Article {
+	var res []*Article
+	for _, a := range c.backlinks[art.URI] {
+		if a.Listed() {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// unmodified returns true if the article was not modified since the previous load.
+func (c *Config) unmodified(art *Article) bool {
+	prev, ok := c.loaded[art.filePath]
+	if !ok {
+		return false
+	}
+	// the previous config can be used by requests
+	prev.mu.Lock()
+	defer prev.mu.Unlock()
+	return !art.indexed.IsZero() && prev.indexed.Equal(art.indexed)
+}
+
+// ImageSizes contains the sizes attribute of images depending on where they are displayed.
+// See frontend/scss/main.scss for the widths.
+var ImageSizes = map[string]string{
+	"content": "(max-width: 814px) calc(100vw - 4rem), 750px",
+	"large":   "(max-width: 1064px) calc(100vw - 4rem), 1000px",
+	"card":    "(max-width: 800px) calc(100vw - 4rem), 484px",
+}
+
+// ResponsiveImage returns the variants of the image stored in PublicFolder.
+// It returns nil if the image is remote or if it cannot be processed.
+func (c *Config) ResponsiveImage(src string) *dom.ResponsiveImg {
+	img, err := c.images.Image(src)
+	if err != nil {
+		slog.Warn("cannot process image", "error", err, "src", src)
+		return nil
+	}
+	return img
+}
+
+// ImageVariant returns the variant of the image stored in PublicFolder with the width w.
+func (c *Config) ImageVariant(src string, w int, webp bool) (*images.Variant, error) {
+	return c.images.Variant(src, w, webp)
+}
+
+// generateImages generates the variants of the images used by the articles.
+// If all is false, the articles which were not modified since the previous load are skipped.
+func (c *Config) generateImages(all bool) {
+	start := time.Now()
+	for _, sec := range c.Sections {
+		for _, art := range sec.All() {
+			if !all && c.unmodified(art) {
+				continue
+			}
+			if len(art.Image.Src) > 0 {
+				c.ResponsiveImage(art.Image.Src)
+			}
+			for _, src := range art.Images() {
+				c.ResponsiveImage(src)
+			}
+		}
+	}
+	slog.Info("images generated", "duration", time.Since(start))
+}
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newSite writes the files of a site with a section logs in a temporary folder.
+// It returns the path of the config file.
+func newSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["config.toml"] = `
+domain = "example.org"
+name = "example"
+data_folder = "` + filepath.Join(dir, "data") + `"
+public_folder = "` + filepath.Join(dir, "public") + `"
+cache_folder = "` + filepath.Join(dir, "cache") + `"
+
+[[section]]
+name = "logs"
+title_name = "log"
+folder = "` + filepath.Join(dir, "data", "logs") + `"
+uri = "logs"
+`
+	for _, folder := range []string{"public", "data/logs"} {
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		fp := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.toml")
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg := LoadConfig(newSite(t, map[string]string{
+		"data/logs/valid.md":   "title = \"Valid\"\npublication_date = 2024-01-01\n---\nSee [[invalid]].\n",
+		"data/logs/invalid.md": "title = \"Invalid\"\npublication_date = 2024-01-02\n---\n**\n\né_*\n\n[[[_\n",
+	}))
+	if cfg == nil {
+		t.Fatal("invalid config")
+	}
+	art := cfg.Section("logs").Get("invalid")
+	if art == nil {
+		t.Fatal("article not loaded")
+	}
+	if len(cfg.Backlinks(art)) != 1 {
+		t.Errorf("invalid backlinks, got %v", cfg.Backlinks(art))
+	}
+	if got := art.Content(); len(got) == 0 {
+		t.Error("empty content")
+	}
+}
@@ -3,6 +3,11 @@ package backend
 import (
 	"bytes"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -23,3 +28,70 @@ func Parse(filePath string) (*Article, error) {
 	}
 	return &art, nil
 }
+
+// git returns the path of git, or an empty string if it is not installed.
+var git = sync.OnceValue(func() string {
+	p, _ := exec.LookPath("git")
+	return p
+})
+
+// history is the git history of the files in a folder.
+type history struct {
+	// commits are the commit times of each file, from the newest
+	commits map[string][]time.Time
+	// modified are the files with uncommitted modifications
+	modified map[string]bool
+}
+
+// gitHistory returns the history of the files in dir, or nil if it is not in a git repository.
+func gitHistory(dir string) *history {
+	if len(git()) == 0 {
+		return nil
+	}
+	out, err := exec.Command(
+		git(), "-c", "core.quotePath=false", "-C", dir, "log", "--relative", "--name-only", "--format=%x00%cI", "--", ".",
+	).Output()
+	if err != nil {
+		return nil
+	}
+	h := &history{commits: make(map[string][]time.Time), modified: make(map[string]bool)}
+	var t time.Time
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if date, ok := strings.CutPrefix(line, "\x00"); ok {
+			t, _ = time.Parse(time.RFC3339, date)
+		} else if len(line) > 0 && !t.IsZero() {
+			h.commits[line] = append(h.commits[line], t)
+		}
+	}
+	out, err = exec.Command(git(), "-C", dir, "ls-files", "--modified", "-z").Output()
+	if err != nil {
+		return nil
+	}
+	for f := range strings.SplitSeq(string(out), "\x00") {
+		h.modified[f] = true
+	}
+	return h
+}
+
+// modTime returns the time of the last modification of the file fp in dir.
+// It uses the git history if the file is tracked and committed, and the modification time of the file otherwise.
+// It returns zero if the file was never modified after its first commit.
+func (h *history) modTime(dir, fp string) time.Time {
+	if h != nil {
+		rel, err := filepath.Rel(dir, fp)
+		commits := h.commits[filepath.ToSlash(rel)]
+		switch {
+		case err != nil || len(commits) == 0 || h.modified[filepath.ToSlash(rel)]:
+			// not tracked or modified since the last commit
+		case len(commits) == 1:
+			return time.Time{}
+		default:
+			return commits[0]
+		}
+	}
+	stat, err := os.Stat(fp)
+	if err != nil {
+		return time.Time{}
+	}
+	return stat.ModTime()
+}
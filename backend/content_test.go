@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// runGit runs git in dir, committing at the time date.
+func runGit(t *testing.T, dir string, date time.Time, args ...string) {
+	t.Helper()
+	cmd := exec.Command(git(), append([]string{"-C", dir, "-c", "commit.gpgsign=false"}, args...)...)
+	d := date.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.org", "GIT_AUTHOR_DATE="+d,
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.org", "GIT_COMMITTER_DATE="+d,
+	)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v, %s", args, err, b)
+	}
+}
+
+// writeFile writes content in fp and sets its modification time to mod.
+func writeFile(t *testing.T, fp, content string, mod time.Time) {
+	t.Helper()
+	if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(fp, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitHistory(t *testing.T) {
+	if len(git()) == 0 {
+		t.Skip("git is not installed")
+	}
+	first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	mod := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	root := t.TempDir()
+	// the section is a subfolder of the repository
+	dir := filepath.Join(root, "logs")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, root, first, "init", "-q")
+	for _, name := range []string{"once.md", "twice.md", "modified.md"} {
+		writeFile(t, filepath.Join(dir, name), "first", mod)
+	}
+	runGit(t, root, first, "add", ".")
+	runGit(t, root, first, "commit", "-q", "-m", "first")
+	writeFile(t, filepath.Join(dir, "twice.md"), "second", mod)
+	runGit(t, root, second, "commit", "-q", "-am", "second")
+	writeFile(t, filepath.Join(dir, "modified.md"), "modified", mod)
+	writeFile(t, filepath.Join(dir, "untracked.md"), "untracked", mod)
+
+	h := gitHistory(dir)
+	if h == nil {
+		t.Fatal("history not read")
+	}
+	fn := func(name string, expected time.Time) func(*testing.T) {
+		return func(t *testing.T) {
+			if got := h.modTime(dir, filepath.Join(dir, name)); !got.Equal(expected) {
+				t.Errorf("invalid modification time, got %s", got)
+			}
+		}
+	}
+	t.Run("one commit", fn("once.md", time.Time{}))
+	t.Run("two commits", fn("twice.md", second))
+	t.Run("uncommitted", fn("modified.md", mod))
+	t.Run("untracked", fn("untracked.md", mod))
+}
+
+func TestGitHistoryOutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	// git must not find a repository in the parents of dir
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	mod := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fp := filepath.Join(dir, "a.md")
+	writeFile(t, fp, "a", mod)
+	h := gitHistory(dir)
+	if h != nil {
+		t.Errorf("history must be nil outside a repository, got %v", h)
+	}
+	if got := h.modTime(dir, fp); !got.Equal(mod) {
+		t.Errorf("invalid modification time, got %s", got)
+	}
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"errors"
+	"html/template"
+
+	"anhgelus.world/small-web/dom"
+	"anhgelus.world/small-web/markdown"
+)
+
+var (
+	ErrMissingArgument = errors.New("missing argument")
+	ErrBlockDirective  = errors.New("directive must be a block")
+)
+
+// directives available in the articles.
+var directives = map[string]markdown.Directive{
+	"audio":   audioDirective,
+	"gallery": galleryDirective,
+}
+
+// audioDirective embeds an audio file stored in PublicFolder.
+//
+//	{{< audio "file.mp3" title="Title" >}}
+func audioDirective(args *markdown.DirectiveArgs) (template.HTML, error) {
+	if len(args.Args) == 0 {
+		return "", ErrMissingArgument
+	}
+	audio := dom.NewContentElement("audio", nil)
+	audio.SetAttribute("controls", "")
+	audio.SetAttribute("preload", "metadata")
+	audio.SetAttribute("src", template.HTMLEscapeString(args.Option.ImageSource(args.Args[0])))
+	contents := []dom.Element{audio}
+	if title, ok := args.Named["title"]; ok {
+		contents = append(contents, dom.NewLiteralContentElement("figcaption", template.HTML(template.HTMLEscapeString(title))))
+	}
+	figure := dom.NewContentElement("figure", contents)
+	figure.ClassList().Add("audio")
+	return figure.Render(), nil
+}
+
+// galleryDirective displays the images of its body in a grid.
+//
+//	::: gallery
+//	![Alt](image.jpg)
+//
+//	![Alt](other.jpg)
+//	:::
+func galleryDirective(args *markdown.DirectiveArgs) (template.HTML, error) {
+	if !args.Block {
+		return "", ErrBlockDirective
+	}
+	gallery := dom.NewLiteralContentElement("div", args.Body)
+	gallery.ClassList().Add("gallery")
+	return gallery.Render(), nil
+}
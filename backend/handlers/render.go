@@ -14,6 +14,8 @@ import (
 	"strings"
 
 	"anhgelus.world/small-web/backend"
+	"anhgelus.world/small-web/dom"
+	"anhgelus.world/small-web/markdown"
 )
 
 //go:embed templates
@@ -32,6 +34,7 @@ type Data struct {
 	URL             string
 	Image           string
 	PubDate         string
+	ModDate         string
 	Title           string
 	quotes          []string
 	Custom          any
@@ -47,11 +50,21 @@ func funcMap(ctx context.Context) template.FuncMap {
 		"static": getStatic,
 		"fullStatic": func(path string) string {
 			s := getStatic(path)
-			if strings.HasPrefix(s, "https://") {
+			if markdown.ExternalLink.MatchString(s) {
 				return s
 			}
 			return "https://" + cfg.Domain + s
 		},
+		"picture": func(src, alt, sizes string, classes ...string) template.HTML {
+			img := dom.NewImg(
+				template.HTML(template.HTMLEscapeString(getStatic(src))),
+				template.HTML(template.HTMLEscapeString(alt)),
+			)
+			for _, c := range classes {
+				img.ClassList().Add(c)
+			}
+			return dom.NewResponsiveImg(img, cfg.ResponsiveImage(src), backend.ImageSizes[sizes]).Render()
+		},
 		"asset": func(path string) backend.AssetData { return getAsset(ctx, path) },
 		"first": func(sl []*backend.Article) *backend.Article {
 			if len(sl) == 0 {
@@ -65,8 +78,10 @@ func funcMap(ctx context.Context) template.FuncMap {
 			}
 			return sl[1:]
 		},
-		"next":   func(i int) int { return i + 1 },
-		"before": func(i int) int { return i - 1 },
+		"backlinks": cfg.Backlinks,
+		"text":      func() backend.Text { return cfg.Text },
+		"next":      func(i int) int { return i + 1 },
+		"before":    func(i int) int { return i - 1 },
 		"uri": func(p string) string {
 			if len(p) == 0 {
 				return ""
@@ -110,6 +125,7 @@ type RSSData struct {
 	Title       string
 	Description string
 	URI         string
+	URL         string
 	Items       []*backend.Article
 }
 
@@ -127,7 +143,7 @@ func renderRSS(ctx context.Context, w http.ResponseWriter, data RSSData) error {
 }
 
 func getStatic(path string) string {
-	if strings.HasPrefix(path, "https://") {
+	if markdown.ExternalLink.MatchString(path) {
 		return path
 	}
 	return "/static/" + strings.TrimPrefix(path, "/")
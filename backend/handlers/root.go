@@ -43,7 +43,7 @@ func Home() http.Handler {
 func Root() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		cfg := backend.ContextConfig(r.Context())
-		art, err := backend.Parse(
+		art, err := cfg.Parse(
 			path.Join(cfg.DataFolder, r.PathValue("any")+".md"),
 		)
 		if err != nil {
@@ -53,6 +53,10 @@ func Root() http.Handler {
 			}
 			panic(err)
 		}
+		if !art.Visible() {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
 		err = render(r.Context(), w, "simple", Data{
 			Custom: art.Content(),
 			Title:  art.Title,
@@ -73,6 +77,7 @@ func RSS() http.Handler {
 		err := renderRSS(r.Context(), w, RSSData{
 			Title:       cfg.Name,
 			Description: cfg.Description,
+			URL:         r.URL.Path,
 			Items:       items,
 		})
 		if err != nil {
@@ -36,9 +36,19 @@ func paginate(articles []*backend.Article, maxLogs int, r *http.Request) (page i
 	return
 }
 
-func SectionHome(sec *backend.Section) http.Handler {
-	arts := sec.Articles()
+// section returns the section named name in the config of the request.
+func section(r *http.Request, name string) *backend.Section {
+	return backend.ContextConfig(r.Context()).Section(name)
+}
+
+func SectionHome(name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sec := section(r, name)
+		if sec == nil {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
+		arts := sec.Articles()
 		page, current := paginate(arts, 7, r)
 		if page < 1 {
 			http.Error(w, "Bad request: invalid page number", http.StatusBadRequest)
@@ -63,8 +73,13 @@ func SectionHome(sec *backend.Section) http.Handler {
 	})
 }
 
-func SectionArticle(sec *backend.Section) http.Handler {
+func SectionArticle(name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sec := section(r, name)
+		if sec == nil {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
 		slug := r.PathValue("slug")
 		art := sec.Get(slug)
 		if art == nil {
@@ -74,7 +89,8 @@ func SectionArticle(sec *backend.Section) http.Handler {
 		err := render(r.Context(), w, "data", Data{
 			Title:   art.Title + " - " + sec.TitleName + " entry",
 			Custom:  art,
-			PubDate: art.PubLocalDate.String(),
+			PubDate: art.PubDate(),
+			ModDate: art.ModDate(),
 		})
 		if err != nil {
 			panic(err)
@@ -82,14 +98,19 @@ func SectionArticle(sec *backend.Section) http.Handler {
 	})
 }
 
-func SectionRSS(sec *backend.Section) http.Handler {
-	items := sec.FirstN(7)
+func SectionRSS(name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sec := section(r, name)
+		if sec == nil {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
 		err := renderRSS(r.Context(), w, RSSData{
 			Title:       sec.Name,
 			Description: sec.Description,
 			URI:         sec.URI,
-			Items:       items,
+			URL:         r.URL.Path,
+			Items:       sec.FirstN(7),
 		})
 		if err != nil {
 			panic(err)
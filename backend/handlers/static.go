@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"embed"
+	"errors"
+	"image"
 	"io/fs"
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"anhgelus.world/ljus"
 	"anhgelus.world/small-web/backend"
+	"anhgelus.world/small-web/backend/images"
 )
 
 // httpEmbedFS is an implementation of fs.FS, fs.ReadDirFS and fs.ReadFileFS helping to manage embed.FS for http server
@@ -52,6 +56,43 @@ func StaticFiles(path string, root fs.FS) ljus.Route {
 	}).SetName("static-files " + path)
 }
 
+// Images serves the variants of the images stored in PublicFolder, generating them on first request.
+// The width is given by the query parameter w, and f=webp requests a WebP variant.
+func Images(path string) ljus.Route {
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	return ljus.NewRouteFunc(path+"{file...}", serveImage).SetName("images " + path)
+}
+
+// serveImage serves the variant of the image in the path value file.
+func serveImage(w http.ResponseWriter, req *http.Request) {
+	cfg := backend.ContextConfig(req.Context())
+	q := req.URL.Query()
+	width, err := strconv.Atoi(q.Get("w"))
+	if err != nil {
+		NotFound().ServeHTTP(w, req)
+		return
+	}
+	v, err := cfg.ImageVariant(req.PathValue("file"), width, q.Get("f") == "webp")
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, image.ErrFormat) || errors.Is(err, images.ErrInvalidVariant) {
+		NotFound().ServeHTTP(w, req)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+	w.Header().Set("ETag", `"`+v.ETag+`"`)
+	if q.Get("v") == v.Key {
+		// v changes when the image is modified
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	// handles If-None-Match with the ETag
+	http.ServeFile(w, req, v.Path)
+}
+
 func TxtFiles() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"anhgelus.world/small-web/backend"
+)
+
+// newConfig loads a site with the images in its public folder.
+func newConfig(t *testing.T, images map[string][]byte) *backend.Config {
+	t.Helper()
+	dir := t.TempDir()
+	for _, folder := range []string{"public", "data/logs"} {
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, b := range images {
+		if err := os.WriteFile(filepath.Join(dir, "public", name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := `
+domain = "example.org"
+name = "example"
+quotes = ["quote"]
+data_folder = "` + filepath.Join(dir, "data") + `"
+public_folder = "` + filepath.Join(dir, "public") + `"
+cache_folder = "` + filepath.Join(dir, "cache") + `"
+
+[[section]]
+name = "logs"
+title_name = "log"
+folder = "` + filepath.Join(dir, "data", "logs") + `"
+uri = "logs"
+`
+	fp := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(fp, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := backend.LoadConfig(fp)
+	if cfg == nil {
+		t.Fatal("invalid config")
+	}
+	return cfg
+}
+
+// newJPEG encodes an image w×h with the EXIF orientation o.
+func newJPEG(t *testing.T, w, h, o int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	// big endian TIFF header with one IFD containing the orientation
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(o))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(2+6+len(tiff)))
+	app1 = append(app1, "Exif\x00\x00"...)
+	app1 = append(app1, tiff...)
+	return slices.Concat(b[:2], app1, b[2:])
+}
+
+func serveImageTest(t *testing.T, cfg *backend.Config, file, query string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	ctx := backend.SetContext(context.Background(), cfg, nil, false, nil)
+	ctx = backend.SetContextAssetsFS(ctx, fstest.MapFS{})
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/img/"+file+"?"+query, nil)
+	req.SetPathValue("file", file)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	serveImage(w, req)
+	return w
+}
+
+func TestImages(t *testing.T) {
+	cfg := newConfig(t, map[string][]byte{
+		"a.jpg":       newJPEG(t, 1000, 500, 1),
+		"rotated.jpg": newJPEG(t, 1000, 500, 6),
+	})
+	v, err := cfg.ImageVariant("a.jpg", 480, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := `"` + v.ETag + `"`
+
+	t.Run("width", func(t *testing.T) {
+		for _, q := range []string{"", "w=abc", "w=500", "w=1920"} {
+			w := serveImageTest(t, cfg, "a.jpg", q, nil)
+			if len(w.Header().Get("ETag")) != 0 || w.Header().Get("Content-Type") == "image/jpeg" {
+				t.Errorf("%q must not be served", q)
+			}
+		}
+		w := serveImageTest(t, cfg, "a.jpg", "w=1000", nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/jpeg" {
+			t.Errorf("the width of the image must be served, got %d", w.Code)
+		}
+	})
+	t.Run("etag", func(t *testing.T) {
+		w := serveImageTest(t, cfg, "a.jpg", "w=480", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("invalid status, got %d", w.Code)
+		}
+		if got := w.Header().Get("ETag"); got != etag {
+			t.Errorf("invalid ETag, got %s", got)
+		}
+		w = serveImageTest(t, cfg, "a.jpg", "w=480", http.Header{"If-None-Match": {etag}})
+		if w.Code != http.StatusNotModified {
+			t.Errorf("invalid status, got %d", w.Code)
+		}
+		w = serveImageTest(t, cfg, "a.jpg", "w=480", http.Header{"If-None-Match": {`"other"`}})
+		if w.Code != http.StatusOK {
+			t.Errorf("invalid status, got %d", w.Code)
+		}
+	})
+	t.Run("cache", func(t *testing.T) {
+		immutable := "public, max-age=31536000, immutable"
+		w := serveImageTest(t, cfg, "a.jpg", "w=480&v="+v.Key, nil)
+		if got := w.Header().Get("Cache-Control"); got != immutable {
+			t.Errorf("invalid Cache-Control, got %s", got)
+		}
+		for _, q := range []string{"w=480", "w=480&v=other"} {
+			w = serveImageTest(t, cfg, "a.jpg", q, nil)
+			if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+				t.Errorf("invalid Cache-Control for %q, got %s", q, got)
+			}
+		}
+	})
+	t.Run("orientation", func(t *testing.T) {
+		w := serveImageTest(t, cfg, "rotated.jpg", "w=480", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("invalid status, got %d", w.Code)
+		}
+		img, err := jpeg.DecodeConfig(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Width != 480 || img.Height != 960 {
+			t.Errorf("invalid dimensions, got %dx%d", img.Width, img.Height)
+		}
+	})
+}
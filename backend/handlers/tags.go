@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"anhgelus.world/small-web/backend"
+	"anhgelus.world/small-web/dom"
+)
+
+type TagsData struct {
+	Tags []backend.Tag
+}
+
+func Tags() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := backend.ContextConfig(r.Context())
+		err := render(r.Context(), w, "tags", Data{Title: "Tags", Custom: TagsData{Tags: cfg.Tags()}})
+		if err != nil {
+			panic(err)
+		}
+	})
+}
+
+// tagSection returns the Section displaying the articles with the tag.
+func tagSection(cfg *backend.Config, tag string) *backend.Section {
+	return &backend.Section{
+		Name:        "#" + tag,
+		TitleName:   "#" + tag,
+		Description: strings.ReplaceAll(cfg.Text.Tag, "{tag}", tag),
+		URI:         "tags/" + tag,
+	}
+}
+
+func Tag() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := r.PathValue("tag")
+		cfg := backend.ContextConfig(r.Context())
+		arts := cfg.Tagged(tag)
+		if len(arts) == 0 {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
+		page, current := paginate(arts, 7, r)
+		if page < 1 {
+			http.Error(w, "Bad request: invalid page number", http.StatusBadRequest)
+			return
+		}
+		v := SectionData{
+			Section:     tagSection(cfg, tag),
+			Articles:    current,
+			Paginate:    true,
+			LenMax:      7,
+			CurrentPage: page,
+			PagesNumber: (len(arts)-1)/7 + 1,
+		}
+		rss := dom.NewVoidElement("link")
+		rss.SetAttribute("rel", "alternate").
+			SetAttribute("href", html.EscapeString(r.URL.EscapedPath()+"/rss")).
+			SetAttribute("type", "application/rss+xml").
+			SetAttribute("title", html.EscapeString("RSS #"+tag))
+		err := render(r.Context(), w, "home_section", Data{Title: "#" + tag, Custom: v, Linked: rss.Render()})
+		if err != nil {
+			panic(err)
+		}
+	})
+}
+
+func TagRSS() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := r.PathValue("tag")
+		cfg := backend.ContextConfig(r.Context())
+		arts := cfg.Tagged(tag)
+		if len(arts) == 0 {
+			NotFound().ServeHTTP(w, r)
+			return
+		}
+		sec := tagSection(cfg, tag)
+		err := renderRSS(r.Context(), w, RSSData{
+			Title:       sec.Name,
+			Description: sec.Description,
+			URI:         sec.URI,
+			URL:         r.URL.Path,
+			Items:       arts[:min(7, len(arts))],
+		})
+		if err != nil {
+			panic(err)
+		}
+	})
+}
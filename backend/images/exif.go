@@ -0,0 +1,112 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+const orientationTag = 0x0112
+
+// orientation returns the EXIF orientation of the JPEG b.
+// It returns 1 (no transformation) if there is no valid orientation.
+func orientation(b []byte) int {
+	if len(b) < 4 || b[0] != 0xFF || b[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(b) && b[i] == 0xFF {
+		marker := b[i+1]
+		// the image data starts after SOS
+		if marker == 0xDA {
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(b[i+2:]))
+		if n < 2 || i+2+n > len(b) {
+			return 1
+		}
+		seg := b[i+4 : i+2+n]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+// tiffOrientation returns the orientation stored in the first IFD of the TIFF header b.
+func tiffOrientation(b []byte) int {
+	if len(b) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(b[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(b[4:]))
+	if ifd < 8 || ifd+2 > len(b) {
+		return 1
+	}
+	n := int(order.Uint16(b[ifd:]))
+	for i := range n {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(b) {
+			return 1
+		}
+		if order.Uint16(b[entry:]) != orientationTag {
+			continue
+		}
+		o := int(order.Uint16(b[entry+8:]))
+		if o < 1 || o > 8 {
+			return 1
+		}
+		return o
+	}
+	return 1
+}
+
+// swapsAxes reports whether the orientation o swaps the width and the height.
+func swapsAxes(o int) bool {
+	return o >= 5
+}
+
+// orient applies the EXIF orientation o to src.
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o <= 1 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if swapsAxes(o) {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch o {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+	return dst
+}
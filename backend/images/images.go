@@ -0,0 +1,372 @@
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	_ "image/gif"
+
+	"anhgelus.world/small-web/dom"
+	"anhgelus.world/small-web/markdown"
+)
+
+// Widths of the generated variants.
+var Widths = []int{480, 960, 1920}
+
+const (
+	jpegQuality = 82
+	webpQuality = "80"
+	webpType    = "image/webp"
+)
+
+// ErrInvalidVariant is returned when the requested variant is never generated.
+var ErrInvalidVariant = errors.New("invalid variant")
+
+// Processor generates the variants of the images stored in a folder.
+// Variants are stored in the cache folder, and they are named after the checksum of the original image.
+type Processor struct {
+	root  string
+	cache string
+	url   string
+	// cwebp is the path of the WebP encoder, WebP variants are not generated if it is empty
+	cwebp string
+	// mu protects sources, the variants of each source are generated while holding its own lock
+	mu      sync.Mutex
+	sources map[string]*source
+}
+
+// source is an image stored in the root folder.
+type source struct {
+	// mu is held while the variants are generated
+	mu      sync.Mutex
+	modTime time.Time
+	// key is the checksum of the image
+	key    string
+	format string
+	// orientation is the EXIF orientation of the image, it is applied before resizing
+	orientation int
+	// width and height are the dimensions of the oriented image
+	width, height int
+	img           *dom.ResponsiveImg
+}
+
+// Variant is a file generated from an image.
+type Variant struct {
+	Path string
+	// ETag is a strong validator of the variant
+	ETag string
+	// Key is the checksum of the original image, it changes when the image is modified
+	Key string
+}
+
+// New creates a Processor for the images in root.
+// Variants are stored in cache and served at url by a handler calling Variant.
+func New(root, cache, url string) (*Processor, error) {
+	err := os.MkdirAll(cache, 0755)
+	if err != nil {
+		return nil, err
+	}
+	p := &Processor{
+		root:    root,
+		cache:   cache,
+		url:     strings.TrimSuffix(url, "/") + "/",
+		sources: make(map[string]*source),
+	}
+	// there is no WebP encoder in the standard library
+	p.cwebp, _ = exec.LookPath("cwebp")
+	return p, nil
+}
+
+// Image returns the variants of the image src, relative to the root folder.
+// Missing variants are generated.
+// It returns nil if src is a remote image.
+func (p *Processor) Image(src string) (*dom.ResponsiveImg, error) {
+	if markdown.ExternalLink.MatchString(src) {
+		return nil, nil
+	}
+	s, j, err := p.source(src)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.img != nil {
+		return s.img, nil
+	}
+	img := &dom.ResponsiveImg{Width: s.width, Height: s.height}
+	if s.format == "gif" {
+		// GIF can be animated
+		s.img = img
+		return img, nil
+	}
+	original := dom.Srcset{}
+	webp := dom.Srcset{Type: webpType}
+	for _, w := range append(p.widths(s), s.width) {
+		_, err = j.resized(w)
+		if err != nil {
+			return nil, err
+		}
+		original.Candidates = append(original.Candidates, dom.ImgCandidate{Src: p.variantURL(j.src, s, w, false), Width: w})
+		if len(p.cwebp) == 0 {
+			continue
+		}
+		_, err = j.webp(w)
+		if err != nil {
+			return nil, err
+		}
+		webp.Candidates = append(webp.Candidates, dom.ImgCandidate{Src: p.variantURL(j.src, s, w, true), Width: w})
+	}
+	// the first srcset supported by the browser is used
+	img.Srcsets = []dom.Srcset{webp, original}
+	s.img = img
+	return img, nil
+}
+
+// Variant returns the variant of the image src with the width w, generating it if needed.
+// The width must be one of Widths smaller than the image or the width of the image.
+// The variant with the width of the image is encoded again, except for GIF which are served as is.
+// It returns ErrInvalidVariant if the variant cannot be generated.
+func (p *Processor) Variant(src string, w int, webp bool) (*Variant, error) {
+	s, j, err := p.source(src)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	valid := w == s.width || (s.format != "gif" && slices.Contains(p.widths(s), w))
+	if webp {
+		valid = valid && s.format != "gif" && len(p.cwebp) > 0
+	}
+	if !valid {
+		return nil, errors.Join(ErrInvalidVariant, fmt.Errorf("image: %s, width: %d", src, w))
+	}
+	v := &Variant{Key: s.key}
+	switch {
+	case webp:
+		v.Path, err = j.webp(w)
+	case s.format == "gif":
+		v.Path = j.fp
+	default:
+		v.Path, err = j.resized(w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.ETag = strings.TrimSuffix(filepath.Base(v.Path), filepath.Ext(v.Path))
+	if v.Path == j.fp {
+		v.ETag = s.key
+	}
+	return v, nil
+}
+
+// source returns the source of src, reading it again if it was modified.
+func (p *Processor) source(src string) (*source, *job, error) {
+	src = strings.TrimPrefix(filepath.Clean("/"+src), "/")
+	fp := filepath.Join(p.root, src)
+	stat, err := os.Stat(fp)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.mu.Lock()
+	s, ok := p.sources[fp]
+	p.mu.Unlock()
+	if !ok || !s.modTime.Equal(stat.ModTime()) {
+		b, err := os.ReadFile(fp)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+		if err != nil {
+			return nil, nil, errors.Join(err, fmt.Errorf("image: %s", src))
+		}
+		sum := sha256.Sum256(b)
+		s = &source{
+			modTime:     stat.ModTime(),
+			key:         hex.EncodeToString(sum[:12]),
+			format:      format,
+			orientation: 1,
+			width:       cfg.Width,
+			height:      cfg.Height,
+		}
+		if format == "jpeg" {
+			s.orientation = orientation(b)
+		}
+		if swapsAxes(s.orientation) {
+			s.width, s.height = s.height, s.width
+		}
+		p.mu.Lock()
+		// another call could have read the same version first
+		if prev, ok := p.sources[fp]; ok && prev.modTime.Equal(s.modTime) {
+			s = prev
+		} else {
+			p.sources[fp] = s
+		}
+		p.mu.Unlock()
+	}
+	return s, &job{Processor: p, source: s, src: src, fp: fp}, nil
+}
+
+// widths returns the widths of the resized variants of s.
+func (p *Processor) widths(s *source) []int {
+	var res []int
+	for _, w := range Widths {
+		if w < s.width {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+func (p *Processor) variantURL(src string, s *source, w int, webp bool) string {
+	u := fmt.Sprintf("%s%s?w=%d", p.url, src, w)
+	if webp {
+		u += "&f=webp"
+	}
+	return u + "&v=" + s.key
+}
+
+// job generates the variants of a source, decoding it at most once.
+type job struct {
+	*Processor
+	*source
+	src     string
+	fp      string
+	decoded *image.RGBA
+}
+
+func (j *job) ext() string {
+	if j.format == "jpeg" {
+		return "jpg"
+	}
+	return j.format
+}
+
+// resized returns the path of the variant with the width w in the original format.
+// The image is only encoded again if w is its width.
+func (j *job) resized(w int) (string, error) {
+	out := filepath.Join(j.cache, fmt.Sprintf("%s-%d.%s", j.key, w, j.ext()))
+	if exists(out) {
+		return out, nil
+	}
+	if j.decoded == nil {
+		b, err := os.ReadFile(j.fp)
+		if err != nil {
+			return "", err
+		}
+		j.decoded, err = decode(b)
+		if err != nil {
+			return "", errors.Join(err, fmt.Errorf("image: %s", j.src))
+		}
+		j.decoded = orient(j.decoded, j.orientation)
+	}
+	if w >= j.width {
+		return out, write(out, j.decoded, j.format)
+	}
+	h := max(1, (j.height*w+j.width/2)/j.width)
+	return out, write(out, resize(j.decoded, w, h), j.format)
+}
+
+// webp returns the path of the WebP variant with the width w.
+func (j *job) webp(w int) (string, error) {
+	out := filepath.Join(j.cache, fmt.Sprintf("%s-%d.webp", j.key, w))
+	if exists(out) {
+		return out, nil
+	}
+	in := j.fp
+	// cwebp ignores the EXIF orientation
+	if w < j.width || j.orientation > 1 {
+		var err error
+		in, err = j.resized(w)
+		if err != nil {
+			return "", err
+		}
+	}
+	tmp := out + ".tmp"
+	b, err := exec.Command(j.cwebp, "-quiet", "-q", webpQuality, in, "-o", tmp).CombinedOutput()
+	if err != nil {
+		return "", errors.Join(err, fmt.Errorf("cwebp: %s", b))
+	}
+	return out, os.Rename(tmp, out)
+}
+
+func exists(fp string) bool {
+	_, err := os.Stat(fp)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+func decode(b []byte) (*image.RGBA, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba, nil
+}
+
+// resize scales down src to w×h with a box filter.
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := range w {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for range x1 - x0 {
+					for c := range sum {
+						sum[c] += int(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// write encodes img in fp.
+// The file is written atomically, so it can be served while being generated.
+func write(fp string, img image.Image, format string) error {
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return err
+	}
+	tmp := fp + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
@@ -0,0 +1,206 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+// newProcessor creates a Processor for the images in a temporary folder.
+// The WebP variants are never generated.
+func newProcessor(t *testing.T, files map[string][]byte) *Processor {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "images")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(root, name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := New(root, filepath.Join(dir, "cache"), "/static/images")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.cwebp = ""
+	return p
+}
+
+// newImage returns an image w×h with a red left half and a blue right half.
+func newImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := red
+			if x >= w/2 {
+				c = blue
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeJPEG encodes newImage with the EXIF orientation o.
+func encodeJPEG(t *testing.T, w, h, o int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, newImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	// big endian TIFF header with one IFD containing the orientation
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(o))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(2+6+len(tiff)))
+	app1 = append(app1, "Exif\x00\x00"...)
+	app1 = append(app1, tiff...)
+	return slices.Concat(b[:2], app1, b[2:])
+}
+
+func decodeFile(t *testing.T, fp string) image.Image {
+	t.Helper()
+	f, err := os.Open(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestImage(t *testing.T) {
+	p := newProcessor(t, map[string][]byte{
+		"wide.png":    encodePNG(t, 1000, 10),
+		"small.png":   encodePNG(t, 300, 200),
+		"rotated.jpg": encodeJPEG(t, 100, 50, 6),
+	})
+	fn := func(src string, width, height int, widths ...int) func(*testing.T) {
+		return func(t *testing.T) {
+			img, err := p.Image(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Width != width || img.Height != height {
+				t.Errorf("invalid dimensions, got %dx%d", img.Width, img.Height)
+			}
+			var got []int
+			for _, c := range img.Srcsets[len(img.Srcsets)-1].Candidates {
+				got = append(got, c.Width)
+			}
+			if !slices.Equal(got, widths) {
+				t.Errorf("invalid widths, got %v", got)
+			}
+		}
+	}
+	t.Run("variants", fn("wide.png", 1000, 10, 480, 960, 1000))
+	t.Run("no upscaling", fn("small.png", 300, 200, 300))
+	t.Run("orientation", fn("rotated.jpg", 50, 100, 50))
+	t.Run("remote", func(t *testing.T) {
+		img, err := p.Image("https://example.org/a.png")
+		if img != nil || err != nil {
+			t.Errorf("remote images are not processed, got %v, %v", img, err)
+		}
+	})
+}
+
+func TestVariant(t *testing.T) {
+	p := newProcessor(t, map[string][]byte{
+		"wide.png":    encodePNG(t, 1000, 10),
+		"small.png":   encodePNG(t, 300, 200),
+		"rotated.jpg": encodeJPEG(t, 100, 50, 6),
+	})
+	fn := func(src string, w, width, height int) func(*testing.T) {
+		return func(t *testing.T) {
+			v, err := p.Variant(src, w, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := decodeFile(t, v.Path).Bounds()
+			if b.Dx() != width || b.Dy() != height {
+				t.Errorf("invalid dimensions, got %dx%d", b.Dx(), b.Dy())
+			}
+		}
+	}
+	t.Run("resized", fn("wide.png", 480, 480, 5))
+	t.Run("original", fn("small.png", 300, 300, 200))
+	t.Run("orientation", func(t *testing.T) {
+		fn("rotated.jpg", 50, 50, 100)(t)
+		v, err := p.Variant("rotated.jpg", 50, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the left half is rotated to the top
+		img := decodeFile(t, v.Path)
+		if r, _, b, _ := img.At(25, 10).RGBA(); r < b {
+			t.Errorf("top must be red, got %v", img.At(25, 10))
+		}
+		if r, _, b, _ := img.At(25, 90).RGBA(); r > b {
+			t.Errorf("bottom must be blue, got %v", img.At(25, 90))
+		}
+	})
+	invalid := func(src string, w int, webp bool) func(*testing.T) {
+		return func(t *testing.T) {
+			_, err := p.Variant(src, w, webp)
+			if !errors.Is(err, ErrInvalidVariant) {
+				t.Errorf("expected ErrInvalidVariant, got %v", err)
+			}
+		}
+	}
+	t.Run("upscaled", invalid("small.png", 480, false))
+	t.Run("unknown width", invalid("wide.png", 500, false))
+	t.Run("unrotated width", invalid("rotated.jpg", 100, false))
+	t.Run("webp", invalid("wide.png", 480, true))
+}
+
+func TestOrientation(t *testing.T) {
+	for o := range 9 {
+		got := orientation(encodeJPEG(t, 2, 2, o))
+		expected := o
+		if o == 0 {
+			expected = 1
+		}
+		if got != expected {
+			t.Errorf("invalid orientation for %d, got %d", o, got)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, newImage(2, 2), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := orientation(buf.Bytes()); got != 1 {
+		t.Errorf("missing orientation must be 1, got %d", got)
+	}
+}
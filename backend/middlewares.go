@@ -5,15 +5,18 @@ import (
 	"crypto/subtle"
 	"database/sql"
 	"net/http"
+	"sync/atomic"
 
 	"anhgelus.world/ljus"
 )
 
-func ContextMiddleware(assets map[string]AssetData, cfg *Config, debug bool, db *sql.DB) ljus.Middleware {
+// ContextMiddleware sets the context of the requests.
+// The config is loaded at the start of each request, so it can be replaced while the requests are handled.
+func ContextMiddleware(assets map[string]AssetData, cfg *atomic.Pointer[Config], debug bool, db *sql.DB) ljus.Middleware {
 	return func(next ljus.Handler, w *ljus.StatusWriter, r *http.Request) {
 		ctx := SetContext(
 			r.Context(),
-			cfg,
+			cfg.Load(),
 			assets,
 			debug,
 			db)
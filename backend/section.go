@@ -2,45 +2,69 @@ package backend
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
+	"log/slog"
 	"os"
 	"path"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"anhgelus.world/small-web/dom"
 	"anhgelus.world/small-web/markdown"
 	"github.com/nyttikord/avl"
 	"github.com/pelletier/go-toml/v2"
 )
 
+var ErrSlugCollision = errors.New("slug collision")
+
+// articleKey orders the articles from the newest, and by slug if they are published at the same time.
+type articleKey struct {
+	time time.Time
+	slug string
+}
+
+func compareArticleKeys(a, b articleKey) int {
+	if c := -a.time.Compare(b.time); c != 0 {
+		return c
+	}
+	return strings.Compare(a.slug, b.slug)
+}
+
 type Section struct {
 	Name        string `toml:"name"`
 	TitleName   string `toml:"title_name"`
 	Folder      string `toml:"folder"`
 	Description string `toml:"description"`
 	URI         string `toml:"uri"`
-	articles    *avl.KeyAVL[toml.LocalDate, *Article]
-	slugToDate  map[string]toml.LocalDate
+	articles    *avl.KeyAVL[articleKey, *Article]
+	slugs       map[string]*Article
+	config      *Config
 }
 
 func (s *Section) Get(slug string) *Article {
-	k, ok := s.slugToDate[slug]
-	if !ok {
+	art, ok := s.slugs[slug]
+	if !ok || !art.Visible() {
 		return nil
 	}
-	v := s.articles.Get(k)
-	if v == nil {
-		return nil
-	}
-	return *v
+	return art
 }
 
-func (s *Section) Add(slug string, art *Article) {
-	s.articles.Insert(art.PubLocalDate, art)
-	if s.slugToDate == nil {
-		s.slugToDate = make(map[string]toml.LocalDate)
+// Add the article to the section.
+// It returns ErrSlugCollision if another article has the same slug.
+func (s *Section) Add(slug string, art *Article) error {
+	if prev, ok := s.slugs[slug]; ok {
+		return errors.Join(ErrSlugCollision, fmt.Errorf("slug: %s, files: %s and %s", slug, prev.filePath, art.filePath))
+	}
+	if s.slugs == nil {
+		s.slugs = make(map[string]*Article)
 	}
-	s.slugToDate[slug] = art.PubLocalDate
+	s.slugs[slug] = art
+	s.articles.Insert(articleKey{time: art.PubTime(), slug: slug}, art)
+	return nil
 }
 
 func (s *Section) FirstN(n int) []*Article {
@@ -48,15 +72,33 @@ func (s *Section) FirstN(n int) []*Article {
 	return arts[:min(n, len(arts))]
 }
 
+// Articles returns the listed articles, see Article.Listed.
 func (s *Section) Articles() []*Article {
+	var res []*Article
+	for _, art := range s.articles.Sort() {
+		if art.Listed() {
+			res = append(res, art)
+		}
+	}
+	return res
+}
+
+// All returns every article, including the drafts, the unlisted and the scheduled ones.
+func (s *Section) All() []*Article {
 	return s.articles.Sort()
 }
 
 func (s *Section) Init(basePath string) error {
+	// the history is only read if an article was modified since the previous load
+	hist := sync.OnceValue(func() *history { return gitHistory(basePath) })
+	return s.init(basePath, func(fp string) time.Time {
+		return hist().modTime(basePath, fp)
+	})
+}
+
+func (s *Section) init(basePath string, modTime func(string) time.Time) error {
 	if s.articles == nil {
-		s.articles = avl.NewKey[toml.LocalDate, *Article](func(a, b toml.LocalDate) int {
-			return -a.AsTime(time.Local).Compare(b.AsTime(time.Local))
-		})
+		s.articles = avl.NewKey[articleKey, *Article](compareArticleKeys)
 	}
 	entries, err := os.ReadDir(basePath)
 	if err != nil {
@@ -68,7 +110,7 @@ func (s *Section) Init(basePath string) error {
 		}
 		p := path.Join(basePath, entry.Name())
 		if entry.IsDir() {
-			err = s.Init(p)
+			err = s.init(p, modTime)
 			if err != nil {
 				return err
 			}
@@ -80,7 +122,16 @@ func (s *Section) Init(basePath string) error {
 		}
 		slug := strings.TrimSuffix(entry.Name(), ".md")
 		art.URI = "/" + s.URI + "/" + slug
-		s.Add(slug, art)
+		art.section = s
+		art.config = s.config
+		if s.config == nil || !art.reuse(s.config.loaded[p]) {
+			art.modTime = modTime(p)
+		}
+		art.index()
+		err = s.Add(slug, art)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -102,36 +153,292 @@ type Article struct {
 	Image        ImageHeader                   `toml:"image"`
 	Tags         []string                      `toml:"tags"`
 	PubLocalDate toml.LocalDate                `toml:"publication_date"`
+	PubLocalTime *toml.LocalTime               `toml:"publication_time"` // optional, midnight if it is nil
+	UpdLocalDate *toml.LocalDate               `toml:"updated_date"`     // optional, see Article.Updated
 	Poem         bool                          `toml:"poem"`
+	TOC          bool                          `toml:"toc"`
 	Contributors map[string]ArticleContributor `toml:"contributors"`
-	filePath     string
-	URI          string `toml:"-"`
+	// Draft articles are never displayed
+	Draft bool `toml:"draft"`
+	// Unlisted articles are only reachable by their URL
+	Unlisted bool `toml:"unlisted"`
+	// Language of the article, Config.Language is used if it is empty
+	Language string `toml:"language"`
+	filePath string
+	section  *Section
+	config   *Config
+	modTime  time.Time
+	URI      string `toml:"-"`
+	// outgoing internal links, see Article.index
+	mu        sync.Mutex
+	indexed   time.Time
+	hrefs     []string
+	wikiLinks []string
 }
 
-func (a *Article) Content() template.HTML {
+// Visible returns true if the article is reachable by its URL: it is not a draft and it is published.
+// Articles with a publication date in the future are scheduled, they become visible at this date.
+func (a *Article) Visible() bool {
+	return !a.Draft && !a.PubTime().After(time.Now())
+}
+
+// PubTime returns the publication time of the article.
+func (a *Article) PubTime() time.Time {
+	if a.PubLocalTime == nil {
+		return a.PubLocalDate.AsTime(time.Local)
+	}
+	return toml.LocalDateTime{LocalDate: a.PubLocalDate, LocalTime: *a.PubLocalTime}.AsTime(time.Local)
+}
+
+// Updated returns the time of the last modification of the article, or zero if it was never modified after its
+// publication.
+// It is updated_date if it is set, or the time of the last modification of the file.
+func (a *Article) Updated() time.Time {
+	pub := a.PubTime()
+	if a.UpdLocalDate != nil {
+		t := a.UpdLocalDate.AsTime(time.Local)
+		if t.After(pub) {
+			return t
+		}
+		return time.Time{}
+	}
+	// without publication time, the article can be written during the day of its publication
+	if !a.modTime.After(pub) || (a.PubLocalTime == nil && a.modTime.Before(pub.AddDate(0, 0, 1))) {
+		return time.Time{}
+	}
+	return a.modTime
+}
+
+// ModDate returns the date of the last modification of the article, or an empty string if it was never modified.
+func (a *Article) ModDate() string {
+	t := a.Updated()
+	if t.IsZero() {
+		return ""
+	}
+	if a.UpdLocalDate != nil {
+		return a.UpdLocalDate.String()
+	}
+	return t.Format(time.RFC3339)
+}
+
+// PubDate returns the publication date of the article, with its time if it is set.
+func (a *Article) PubDate() string {
+	if a.PubLocalTime == nil {
+		return a.PubLocalDate.String()
+	}
+	return a.PubTime().Format(time.RFC3339)
+}
+
+// Listed returns true if the article is visible and not unlisted.
+// Listed articles are displayed in the sections, in the RSS feeds and they are synced with ATProto.
+func (a *Article) Listed() bool {
+	return a.Visible() && !a.Unlisted
+}
+
+// body returns the markdown of the article and the Option used to parse it.
+func (a *Article) body() ([]byte, *markdown.Option) {
 	b, err := os.ReadFile(a.filePath)
 	if err != nil {
 		panic(err)
 	}
-	_, n, ok := bytes.Cut(b, []byte("---"))
+	var opt markdown.Option
+	if a.config != nil {
+		opt = a.config.markdown
+	}
+	opt.Poem = a.Poem
+	opt.File = a.filePath
+	opt.Lenient = true
+	if len(a.Language) > 0 {
+		opt.Typography = a.Language
+	}
+	if opt.WikiLink != nil {
+		opt.WikiLink = a.wikiLink
+	}
+	front, n, ok := bytes.Cut(b, []byte("---"))
 	if ok {
 		b = n
+		opt.LineOffset = bytes.Count(front, []byte("\n"))
+	}
+	return b, &opt
+}
+
+// Rendered is the result of the rendering of an Article.
+type Rendered struct {
+	Content template.HTML
+	// TableOfContents is empty if it was not asked in the front matter.
+	TableOfContents template.HTML
+}
+
+// Render parses the article once and returns its content with its table of contents.
+// If the markdown is invalid, the error is logged and the source is rendered as preformatted text.
+func (a *Article) Render() Rendered {
+	b, opt := a.body()
+	doc, mdErr := markdown.ParseDocument(string(b), opt)
+	var res Rendered
+	if mdErr == nil {
+		res.Content, mdErr = doc.HTML()
+	}
+	if mdErr != nil {
+		slog.Error("cannot parse markdown", "error", mdErr, "path", a.filePath)
+		return Rendered{Content: rawContent(b)}
+	}
+	for _, w := range doc.Warnings() {
+		slog.Warn("invalid markdown", "error", w)
+	}
+	if a.TOC {
+		res.TableOfContents = doc.Outline().Render()
+	}
+	return res
+}
+
+// Content returns the rendered content of the article.
+func (a *Article) Content() template.HTML {
+	return a.Render().Content
+}
+
+// rawContent renders the markdown source as preformatted text.
+func rawContent(b []byte) template.HTML {
+	return dom.NewLiteralContentElement("pre", template.HTML(template.HTMLEscapeString(string(b)))).Render()
+}
+
+// Images returns the source of every image in the content.
+func (a *Article) Images() []string {
+	doc, err := a.parse()
+	if err != nil {
+		return nil
 	}
-	res, mdErr := markdown.ParseBytes(b, &markdown.Option{Poem: a.Poem})
+	var res []string
+	markdown.Inspect(doc, func(n markdown.Node) bool {
+		if n != nil && n.Kind() == markdown.KindImage {
+			res = append(res, n.Attr("src"))
+		}
+		return true
+	})
+	return res
+}
+
+// parse parses the markdown of the article without rendering it.
+// A panic while parsing is returned as an error, so an invalid article cannot stop the server when it is indexed.
+func (a *Article) parse() (doc *markdown.Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parsing %s: %v", a.filePath, r)
+		}
+	}()
+	b, opt := a.body()
+	doc, mdErr := markdown.ParseDocument(string(b), opt)
 	if mdErr != nil {
-		println(mdErr.Pretty())
-		panic("cannot parse markdown (see logs)")
+		return nil, mdErr
+	}
+	return doc, nil
+}
+
+// index parses the outgoing internal links of the content if the file was modified since the last call.
+// It returns the internal hrefs and the targets of the wiki links.
+func (a *Article) index() ([]string, []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stat, err := os.Stat(a.filePath)
+	if err != nil || stat.ModTime().Equal(a.indexed) {
+		return a.hrefs, a.wikiLinks
+	}
+	doc, err := a.parse()
+	if err != nil {
+		slog.Error("cannot index article", "error", err, "path", a.filePath)
+		// it is indexed again when the file is modified
+		a.indexed = stat.ModTime()
+		return a.hrefs, a.wikiLinks
+	}
+	var hrefs, wikiLinks []string
+	markdown.Inspect(doc, func(n markdown.Node) bool {
+		if n == nil {
+			return true
+		}
+		switch n.Kind() {
+		case markdown.KindLink:
+			href := n.Attr("href")
+			if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
+				href, _, _ = strings.Cut(href, "#")
+				href, _, _ = strings.Cut(href, "?")
+				hrefs = append(hrefs, strings.TrimSuffix(href, "/"))
+			}
+		case markdown.KindWikiLink:
+			wikiLinks = append(wikiLinks, n.Attr("target"))
+		}
+		return true
+	})
+	a.hrefs, a.wikiLinks, a.indexed = hrefs, wikiLinks, stat.ModTime()
+	return hrefs, wikiLinks
+}
+
+// reuse copies the cached data of prev, the same article loaded before, if the file was not modified since.
+func (a *Article) reuse(prev *Article) bool {
+	if prev == nil {
+		return false
+	}
+	stat, err := os.Stat(a.filePath)
+	if err != nil {
+		return false
+	}
+	prev.mu.Lock()
+	defer prev.mu.Unlock()
+	if prev.indexed.IsZero() || !prev.indexed.Equal(stat.ModTime()) {
+		return false
+	}
+	a.modTime = prev.modTime
+	a.indexed, a.hrefs, a.wikiLinks = prev.indexed, prev.hrefs, prev.wikiLinks
+	return true
+}
+
+// WikiLinks returns the target of every wiki link in the content.
+func (a *Article) WikiLinks() []string {
+	_, wikiLinks := a.index()
+	return wikiLinks
+}
+
+// Links returns the URI of every article linked by the content, with markdown links or with wiki links.
+func (a *Article) Links() []string {
+	hrefs, wikiLinks := a.index()
+	res := slices.Clone(hrefs)
+	for _, target := range wikiLinks {
+		slug, _, _ := strings.Cut(target, "#")
+		if uri, _, ok := a.wikiLink(slug); ok {
+			res = append(res, uri)
+		}
 	}
 	return res
 }
 
-var now = time.Now()
+// wikiLink resolves the target of a wiki link.
+// A target without section is in the section of the article.
+func (a *Article) wikiLink(target string) (string, string, bool) {
+	if !strings.Contains(target, "/") && a.section != nil {
+		target = a.section.Name + "/" + target
+	}
+	if a.config == nil {
+		return "", "", false
+	}
+	return a.config.WikiLink(target)
+}
+
+// Lint parses the article in strict mode.
+func (a *Article) Lint() *markdown.ParseError {
+	b, opt := a.body()
+	opt.Lenient = false
+	_, err := markdown.ParseBytes(b, opt)
+	return err
+}
 
 func (a *Article) PubDateRSS() string {
-	t := a.PubLocalDate.AsTime(time.Local)
-	// if same day, assume that it's published now
-	if t.Year() == now.Year() && t.Month() == now.Month() && t.Day() == now.Day() {
-		t = now
+	return a.PubTime().Format(time.RFC1123Z) // because RFC822 in go isn't RFC822???
+}
+
+// UpdatedRSS returns the date of the last modification in the format of Atom, or an empty string if it was never
+// modified.
+func (a *Article) UpdatedRSS() string {
+	t := a.Updated()
+	if t.IsZero() {
+		return ""
 	}
-	return t.Format(time.RFC1123Z) // because RFC822 in go isn't RFC822???
+	return t.Format(time.RFC3339)
 }
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// newArticle returns an article published at t.
+func newArticle(t time.Time) *Article {
+	t = t.Local()
+	return &Article{
+		PubLocalDate: toml.LocalDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()},
+		PubLocalTime: &toml.LocalTime{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()},
+	}
+}
+
+func TestArticleVisibility(t *testing.T) {
+	now := time.Now()
+	past := newArticle(now.Add(-24 * time.Hour))
+	draft := newArticle(now.Add(-24 * time.Hour))
+	draft.Draft = true
+	unlisted := newArticle(now.Add(-24 * time.Hour))
+	unlisted.Unlisted = true
+	scheduled := newArticle(now.Add(time.Hour))
+	scheduledDay := newArticle(now.Add(48 * time.Hour))
+	scheduledDay.PubLocalTime = nil
+	fn := func(art *Article, visible, listed bool) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			if got := art.Visible(); got != visible {
+				t.Errorf("invalid visibility, got %v", got)
+			}
+			if got := art.Listed(); got != listed {
+				t.Errorf("invalid listing, got %v", got)
+			}
+		}
+	}
+	t.Run("published", fn(past, true, true))
+	t.Run("draft", fn(draft, false, false))
+	t.Run("unlisted", fn(unlisted, true, false))
+	t.Run("scheduled", fn(scheduled, false, false))
+	t.Run("scheduled without time", fn(scheduledDay, false, false))
+}
+
+// writeArticles writes the articles in a temporary folder and returns it.
+func writeArticles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		fp := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSectionSlugCollision(t *testing.T) {
+	dir := writeArticles(t, map[string]string{
+		"a.md":     "title = \"A\"\npublication_date = 2024-01-01\n---\nA\n",
+		"sub/a.md": "title = \"Other A\"\npublication_date = 2024-01-02\n---\nA\n",
+	})
+	s := &Section{Name: "logs", URI: "logs"}
+	if err := s.Init(dir); !errors.Is(err, ErrSlugCollision) {
+		t.Errorf("expected ErrSlugCollision, got %v", err)
+	}
+}
+
+func TestSectionOrder(t *testing.T) {
+	dir := writeArticles(t, map[string]string{
+		"b.md":     "title = \"B\"\npublication_date = 2024-01-01\n---\nB\n",
+		"a.md":     "title = \"A\"\npublication_date = 2024-01-01\n---\nA\n",
+		"sub/c.md": "title = \"C\"\npublication_date = 2024-01-01\npublication_time = 12:00:00\n---\nC\n",
+		"d.md":     "title = \"D\"\npublication_date = 2024-01-02\n---\nD\n",
+	})
+	s := &Section{Name: "logs", URI: "logs"}
+	if err := s.Init(dir); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, art := range s.Articles() {
+		got = append(got, art.Title)
+	}
+	// from the newest, and by slug if they are published at the same time
+	expected := []string{"D", "C", "A", "B"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("invalid order, got %v", got)
+	}
+}
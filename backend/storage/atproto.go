@@ -57,3 +57,8 @@ func SetPublishedDocument(ctx context.Context, db *sql.DB, doc PublishedDocument
 		doc.Path, doc.RecordKey, doc.CID.String(), doc.ImageUploaded)
 	return err
 }
+
+func DeletePublishedDocument(ctx context.Context, db *sql.DB, path string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM atproto_documents WHERE path = ?", path)
+	return err
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"slices"
+	"strings"
+)
+
+// Tag is used by the articles.
+type Tag struct {
+	Name string
+	// Count is the number of listed articles with this tag
+	Count int
+}
+
+// Tags returns the tags of the listed articles of every section, sorted by name.
+func (c *Config) Tags() []Tag {
+	counts := make(map[string]int)
+	for _, sec := range c.Sections {
+		for _, art := range sec.Articles() {
+			for _, t := range art.Tags {
+				counts[t]++
+			}
+		}
+	}
+	res := make([]Tag, 0, len(counts))
+	for name, n := range counts {
+		res = append(res, Tag{Name: name, Count: n})
+	}
+	slices.SortFunc(res, func(a, b Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res
+}
+
+// Tagged returns the listed articles of every section with the tag, from the newest.
+func (c *Config) Tagged(tag string) []*Article {
+	var res []*Article
+	for _, sec := range c.Sections {
+		for _, art := range sec.Articles() {
+			if slices.Contains(art.Tags, tag) {
+				res = append(res, art)
+			}
+		}
+	}
+	slices.SortStableFunc(res, func(a, b *Article) int {
+		if c := b.PubTime().Compare(a.PubTime()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.URI, b.URI)
+	})
+	return res
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watch checks every interval if the config file p, the DataFolder or a Section.Folder of cfg were modified.
+// If they were, the config is loaded again and given to reload.
+// Only the modified articles are indexed again, see loadConfig.
+// The previous config is kept if the new one is invalid.
+// Routes are not modified: a section added to the new config is only served after a restart.
+// It returns when ctx is done.
+func Watch(ctx context.Context, p string, cfg *Config, interval time.Duration, reload func(*Config)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := cfg.files(p)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		files := cfg.files(p)
+		if maps.Equal(last, files) {
+			continue
+		}
+		last = files
+		// LoadConfig creates a new config if it does not exist
+		if _, err := os.Stat(p); err != nil {
+			slog.Error("cannot reload config", "error", err, "path", p)
+			continue
+		}
+		start := time.Now()
+		c := func() *Config {
+			// an invalid article must not stop the server
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("cannot reload config", "error", r, "path", p)
+				}
+			}()
+			return loadConfig(p, cfg)
+		}()
+		if c == nil {
+			slog.Error("invalid config, keeping the previous one", "path", p)
+			continue
+		}
+		cfg = c
+		last = cfg.files(p)
+		reload(cfg)
+		slog.Info("config reloaded", "duration", time.Since(start))
+	}
+}
+
+// files returns the modification time of the config file p and of the files in the folders used by the config.
+func (c *Config) files(p string) map[string]time.Time {
+	res := make(map[string]time.Time)
+	if stat, err := os.Stat(p); err == nil {
+		res[p] = stat.ModTime()
+	}
+	add := func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the folder can be modified during the walk
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			res[fp] = info.ModTime()
+		}
+		return nil
+	}
+	folders := []string{c.DataFolder}
+	for _, sec := range c.Sections {
+		folders = append(folders, sec.Folder)
+	}
+	for _, f := range folders {
+		if len(f) > 0 {
+			filepath.WalkDir(f, add)
+		}
+	}
+	return res
+}
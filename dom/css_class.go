@@ -1,6 +1,10 @@
 package dom
 
-import "strings"
+import (
+	"maps"
+	"slices"
+	"strings"
+)
 
 type ClassList map[string]struct{}
 
@@ -8,12 +12,7 @@ func (cl ClassList) set(e Element) Element {
 	if len(cl) == 0 {
 		return e
 	}
-	classes := ""
-	for k := range cl {
-		classes += k + " "
-	}
-	classes = strings.TrimSpace(classes)
-	return e.SetAttribute("class", classes)
+	return e.SetAttribute("class", strings.Join(slices.Sorted(maps.Keys(cl)), " "))
 }
 
 func (cl ClassList) Has(v string) bool {
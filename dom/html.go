@@ -3,12 +3,13 @@ package dom
 import (
 	"fmt"
 	"html/template"
+	"slices"
 )
 
-func render(tag string, attributes map[string]string) template.HTML {
+func render(tag string, attributes map[string]string, order []string) template.HTML {
 	base := fmt.Sprintf(`<%s`, tag)
-	for k, v := range attributes {
-		base = fmt.Sprintf(`%s %s="%s"`, base, k, v)
+	for _, k := range order {
+		base = fmt.Sprintf(`%s %s="%s"`, base, k, attributes[k])
 	}
 	return template.HTML(base + `>`)
 }
@@ -50,12 +51,22 @@ func NewLiteralElement(s template.HTML) LiteralElement {
 type VoidElement struct {
 	Tag        string
 	attributes map[string]string
-	cl         ClassList
+	// order keeps attributes in insertion order, so the output is stable; nil is treated as empty
+	order *[]string
+	cl    ClassList
 }
 
 func (e VoidElement) Render() template.HTML {
 	e.cl.set(e)
-	return render(e.Tag, e.attributes)
+	return render(e.Tag, e.attributes, e.keys())
+}
+
+// keys returns the attributes in insertion order.
+func (e VoidElement) keys() []string {
+	if e.order == nil {
+		return nil
+	}
+	return *e.order
 }
 
 func (e VoidElement) HasAttribute(k string) bool {
@@ -64,12 +75,18 @@ func (e VoidElement) HasAttribute(k string) bool {
 }
 
 func (e VoidElement) SetAttribute(k, v string) Element {
+	if !e.HasAttribute(k) && e.order != nil {
+		*e.order = append(*e.order, k)
+	}
 	e.attributes[k] = v
 	return e
 }
 
 func (e VoidElement) RemoveAttribute(k string) Element {
 	delete(e.attributes, k)
+	if e.order != nil {
+		*e.order = slices.DeleteFunc(*e.order, func(s string) bool { return s == k })
+	}
 	return e
 }
 
@@ -78,7 +95,7 @@ func (e VoidElement) ClassList() ClassList {
 }
 
 func NewVoidElement(tag string) VoidElement {
-	return VoidElement{tag, make(map[string]string), NewClassList()}
+	return VoidElement{tag, make(map[string]string), new([]string), NewClassList()}
 }
 
 func NewImg(src, alt template.HTML) Element {
@@ -92,7 +109,7 @@ type ContentElement struct {
 
 func (e ContentElement) Render() template.HTML {
 	e.cl.set(e)
-	base := render(e.Tag, e.attributes)
+	base := render(e.Tag, e.attributes, e.keys())
 	for _, el := range e.Contents {
 		base += el.Render()
 	}
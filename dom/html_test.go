@@ -2,6 +2,8 @@ package dom
 
 import (
 	"html/template"
+	"maps"
+	"slices"
 	"testing"
 )
 
@@ -9,7 +11,7 @@ func TestRender(t *testing.T) {
 	fn := func(tag string, attributes map[string]string, expected string) func(*testing.T) {
 		return func(t *testing.T) {
 			t.Parallel()
-			got := string(render(tag, attributes))
+			got := string(render(tag, attributes, slices.Collect(maps.Keys(attributes))))
 			if got != expected {
 				t.Errorf("invalid value, got %s", got)
 			}
@@ -41,8 +43,8 @@ func TestVoidElement(t *testing.T) {
 		return func(t *testing.T) {
 			t.Parallel()
 			e := NewVoidElement(tag)
-			for k, v := range attributes {
-				e.SetAttribute(k, v)
+			for _, k := range slices.Sorted(maps.Keys(attributes)) {
+				e.SetAttribute(k, attributes[k])
 				if !e.HasAttribute(k) {
 					t.Errorf("doesn't not have attribute %s after being added", k)
 				}
@@ -59,7 +61,18 @@ func TestVoidElement(t *testing.T) {
 	})
 	t.Run("attributes", func(t *testing.T) {
 		t.Run("one", fn("img", map[string]string{"src": "link"}, `<img src="link">`))
-		t.Run("two", fn("img", map[string]string{"src": "link", "alt": "well"}, `<img src="link" alt="well">`))
+		t.Run("two", fn("img", map[string]string{"src": "link", "alt": "well"}, `<img alt="well" src="link">`))
+	})
+	t.Run("zero", func(t *testing.T) {
+		e := VoidElement{Tag: "br"}
+		e.RemoveAttribute("class")
+		if got := string(e.Render()); got != "<br>" {
+			t.Errorf("invalid value, got %s", got)
+		}
+		c := ContentElement{VoidElement: VoidElement{Tag: "p"}}
+		if got := string(c.Render()); got != "<p></p>" {
+			t.Errorf("invalid value, got %s", got)
+		}
 	})
 }
 
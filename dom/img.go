@@ -0,0 +1,69 @@
+package dom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImgCandidate is an image candidate of a srcset.
+type ImgCandidate struct {
+	Src   string
+	Width int
+}
+
+// Srcset is a list of candidates sharing the same MIME type.
+type Srcset struct {
+	// Type is empty for the format of the original image
+	Type       string
+	Candidates []ImgCandidate
+}
+
+func (s Srcset) String() string {
+	c := make([]string, len(s.Candidates))
+	for i, v := range s.Candidates {
+		c[i] = fmt.Sprintf("%s %dw", v.Src, v.Width)
+	}
+	return strings.Join(c, ", ")
+}
+
+// ResponsiveImg describes the variants of an image.
+type ResponsiveImg struct {
+	Width   int
+	Height  int
+	Srcsets []Srcset
+}
+
+// NewResponsiveImg adds the attributes of a responsive image to img, created with NewImg.
+// sizes is the value of the sizes attribute, used if there are variants.
+// It returns a picture element if there are variants in other formats.
+// img is lazy loaded even if info is nil.
+func NewResponsiveImg(img Element, info *ResponsiveImg, sizes string) Element {
+	img.SetAttribute("loading", "lazy")
+	if info == nil {
+		return img
+	}
+	if info.Width > 0 && info.Height > 0 {
+		img.SetAttribute("width", strconv.Itoa(info.Width))
+		img.SetAttribute("height", strconv.Itoa(info.Height))
+	}
+	var sources []Element
+	for _, s := range info.Srcsets {
+		if len(s.Candidates) == 0 {
+			continue
+		}
+		if len(s.Type) == 0 {
+			img.SetAttribute("srcset", s.String())
+			img.SetAttribute("sizes", sizes)
+			continue
+		}
+		sources = append(sources, NewVoidElement("source").
+			SetAttribute("type", s.Type).
+			SetAttribute("srcset", s.String()).
+			SetAttribute("sizes", sizes))
+	}
+	if len(sources) == 0 {
+		return img
+	}
+	return NewContentElement("picture", append(sources, img))
+}
@@ -0,0 +1,29 @@
+package dom
+
+import "testing"
+
+func TestResponsiveImg(t *testing.T) {
+	fn := func(info *ResponsiveImg, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			got := string(NewResponsiveImg(NewImg("a.jpg", "alt"), info, "100vw").Render())
+			if got != expected {
+				t.Errorf("invalid value, got %s", got)
+			}
+		}
+	}
+	jpeg := Srcset{Candidates: []ImgCandidate{{"a-480.jpg", 480}, {"a.jpg", 1000}}}
+	webp := Srcset{Type: "image/webp", Candidates: []ImgCandidate{{"a-480.webp", 480}, {"a.webp", 1000}}}
+	t.Run("responsive", func(t *testing.T) {
+		t.Run("nil", fn(nil, `<img alt="alt" src="a.jpg" loading="lazy">`))
+		t.Run("dimensions", fn(&ResponsiveImg{Width: 1000, Height: 500},
+			`<img alt="alt" src="a.jpg" loading="lazy" width="1000" height="500">`))
+		t.Run("srcset", fn(&ResponsiveImg{Width: 1000, Height: 500, Srcsets: []Srcset{jpeg}},
+			`<img alt="alt" src="a.jpg" loading="lazy" width="1000" height="500" `+
+				`srcset="a-480.jpg 480w, a.jpg 1000w" sizes="100vw">`))
+		t.Run("picture", fn(&ResponsiveImg{Width: 1000, Height: 500, Srcsets: []Srcset{webp, jpeg}},
+			`<picture><source type="image/webp" srcset="a-480.webp 480w, a.webp 1000w" sizes="100vw">`+
+				`<img alt="alt" src="a.jpg" loading="lazy" width="1000" height="500" `+
+				`srcset="a-480.jpg 480w, a.jpg 1000w" sizes="100vw"></picture>`))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"embed"
 	"flag"
+	"fmt"
 	"io"
 	"log/slog"
 	"log/syslog"
@@ -15,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -39,19 +41,23 @@ var (
 	address    = ":8000"
 	dev        = false
 	sync       = false
+	lint       = false
 	fcgi       = false
 	toSyslog   = false
 	verbose    = false
+	watch      = 2 * time.Second
 )
 
 func init() {
 	flag.StringVar(&configFile, "config", configFile, "config file")
 	flag.StringVar(&address, "address", address, "address to listen to")
 	flag.BoolVar(&dev, "dev", dev, "development mode")
-	flag.BoolVar(&sync, "sync", sync, "sync everything with stored data in ATProto PDS")
+	flag.BoolVar(&sync, "sync", sync, "sync everything with stored data in ATProto PDS, run it again when scheduled articles are published")
+	flag.BoolVar(&lint, "lint", lint, "check every article in strict mode and exit")
 	flag.BoolVar(&fcgi, "fcgi", fcgi, "use fcgi")
 	flag.BoolVar(&toSyslog, "syslog", toSyslog, "log to syslog instead of stderr")
 	flag.BoolVar(&verbose, "v", verbose, "increase verbosity")
+	flag.DurationVar(&watch, "watch", watch, "interval between the checks of modified content, 0 to disable (new sections need a restart)")
 }
 
 func main() {
@@ -81,6 +87,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if lint {
+		os.Exit(lintArticles(cfg))
+	}
+
 	ctx, cancelMigration := context.WithTimeout(
 		context.Background(),
 		15*time.Second)
@@ -137,7 +147,9 @@ func main() {
 	if !dev {
 		r.Use(middleware.SecurityHeaders(cfg.Domain, 24*time.Hour))
 	}
-	r.Use(backend.ContextMiddleware(handlers.Assets, cfg, dev, db),
+	var current atomic.Pointer[backend.Config]
+	current.Store(cfg)
+	r.Use(backend.ContextMiddleware(handlers.Assets, &current, dev, db),
 		backend.RateLimitMiddleware(),
 		storage.StatsMiddleware())
 
@@ -160,16 +172,23 @@ func main() {
 	}).SetName("any-catcher"))
 	r.Handle(ljus.NewRoute("GET /admin", handlers.Admin()).SetName("admin"))
 
+	tags := ljus.NewGroup("GET /tags/")
+	tags.Add(ljus.NewRoute("GET /{$}", handlers.Tags()).SetName("root"))
+	tags.Add(ljus.NewRoute("GET /{tag}", handlers.Tag()).SetName("tag"))
+	tags.Add(ljus.NewRoute("GET /{tag}/rss", handlers.TagRSS()).SetName("rss"))
+	r.Handle(tags.SetName("tags"))
+
 	for _, sec := range cfg.Sections {
 		g := ljus.NewGroup("GET /" + sec.Name + "/")
-		g.Add(ljus.NewRoute("GET /{$}", handlers.SectionHome(sec)).SetName("root"))
-		g.Add(ljus.NewRoute("/{slug}", handlers.SectionArticle(sec)).SetName("article"))
-		g.Add(ljus.NewRoute("GET /rss", handlers.SectionRSS(sec)).SetName("rss"))
+		g.Add(ljus.NewRoute("GET /{$}", handlers.SectionHome(sec.Name)).SetName("root"))
+		g.Add(ljus.NewRoute("/{slug}", handlers.SectionArticle(sec.Name)).SetName("article"))
+		g.Add(ljus.NewRoute("GET /rss", handlers.SectionRSS(sec.Name)).SetName("rss"))
 		r.Handle(g.SetName("section " + sec.Name))
 	}
 
 	r.Handle(handlers.StaticFiles("/assets", assetsFS),
-		handlers.StaticFiles("/static", os.DirFS(cfg.PublicFolder)))
+		handlers.StaticFiles("/static", os.DirFS(cfg.PublicFolder)),
+		handlers.Images("/img"))
 
 	slog.Info("starting http server")
 
@@ -179,6 +198,18 @@ func main() {
 	defer cancel()
 	ctx = backend.SetContextAssetsFS(ctx, assetsFS)
 
+	if watch > 0 {
+		go backend.Watch(ctx, configFile, cfg, watch, func(c *backend.Config) {
+			// routes are created at the start, removed sections are not found because handlers look them up in c
+			for _, sec := range c.Sections {
+				if cfg.Section(sec.Name) == nil {
+					slog.Warn("section added, restart the server to serve it", "section", sec.Name)
+				}
+			}
+			current.Store(c)
+		})
+	}
+
 	var l net.Listener
 	if strings.HasPrefix(address, "/") {
 		l, err = ljus.ListenSocket(address, 0o666)
@@ -232,12 +263,17 @@ func publishDoc(
 	if v, ok := docs[art.URI]; ok && v.ImageUploaded {
 		imgPath = nil
 	}
+	var updatedAt *time.Time
+	if t := art.Updated(); !t.IsZero() {
+		updatedAt = &t
+	}
 	res, rkey, err := s.PublishDoc(
 		ctx,
 		client,
 		art.Title,
 		art.URI,
-		art.PubLocalDate.AsTime(time.Local),
+		art.PubTime(),
+		updatedAt,
 		art.Description,
 		imgPath,
 		art.Tags,
@@ -282,6 +318,8 @@ func xrpcClient(ctx context.Context, cfg *backend.Config, did *atproto.DID) xrpc
 	return client
 }
 
+// syncDocuments publishes the listed articles in the ATProto PDS and deletes the documents of the other ones.
+// Scheduled articles are only published by the first sync after their publication date.
 func syncDocuments(ctx context.Context, db *sql.DB, cfg *backend.Config, did *atproto.DID) {
 	docs, err := storage.PublishedDocuments(ctx, db)
 	if err != nil {
@@ -334,11 +372,46 @@ func syncDocuments(ctx context.Context, db *sql.DB, cfg *backend.Config, did *at
 	if err != nil {
 		panic(err)
 	}
+	listed := make(map[string]bool)
 	for _, sec := range cfg.Sections {
 		for _, art := range sec.Articles() {
 			publishDoc(ctx, client, db, docs, cfg, did, s, art)
+			listed[art.URI] = true
 		}
 		slog.Info("syncing done", "section", sec.Name)
 	}
+	// drafts, unlisted, scheduled and removed articles
+	for p, doc := range docs {
+		if listed[p] {
+			continue
+		}
+		err = s.DeleteDoc(ctx, client, doc.RecordKey)
+		if err != nil {
+			panic(err)
+		}
+		err = storage.DeletePublishedDocument(ctx, db, p)
+		if err != nil {
+			panic(err)
+		}
+		slog.Info("document deleted", "path", p)
+	}
 	slog.Info("syncing done", "rkey", cfg.ATProto.PublicationRKey)
 }
+
+func lintArticles(cfg *backend.Config) int {
+	n := 0
+	for _, sec := range cfg.Sections {
+		for _, art := range sec.All() {
+			if err := art.Lint(); err != nil {
+				fmt.Fprintln(os.Stderr, err.Pretty())
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		slog.Error("invalid articles", "count", n)
+		return 1
+	}
+	slog.Info("every article is valid")
+	return 0
+}
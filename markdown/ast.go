@@ -11,11 +11,13 @@ import (
 var ErrUnkownLexType = errors.New("unkown lex type")
 
 type block interface {
+	Node
 	Eval(*Option) (template.HTML, *ParseError)
 }
 
 type tree struct {
-	blocks []block
+	blocks    []block
+	footnotes []*astFootnoteDef
 }
 
 func (t *tree) Eval(opt *Option) (template.HTML, *ParseError) {
@@ -25,6 +27,21 @@ func (t *tree) Eval(opt *Option) (template.HTML, *ParseError) {
 	if opt.ImageSource == nil {
 		opt.ImageSource = func(s string) string { return s }
 	}
+	// copying options to store the state of this evaluation
+	o := *opt
+	opt = &o
+	var err *ParseError
+	if opt.outline == nil {
+		opt.outline = new(Outline)
+	}
+	if opt.html == nil {
+		opt.html = new(htmlStack)
+	}
+	scope := opt.html.enter()
+	opt.footnotes, err = newFootnotes(t.footnotes, opt.warnings)
+	if err != nil {
+		return "", err
+	}
 	var content template.HTML
 	for _, c := range t.blocks {
 		ct, err := c.Eval(opt)
@@ -33,7 +50,16 @@ func (t *tree) Eval(opt *Option) (template.HTML, *ParseError) {
 		}
 		content += ct
 	}
-	return content, nil
+	ct, err := opt.html.leave(scope, opt)
+	if err != nil {
+		return "", err
+	}
+	content += ct
+	ct, err = opt.footnotes.Eval(opt)
+	if err != nil {
+		return "", err
+	}
+	return content + ct, nil
 }
 
 func (t *tree) String() string {
@@ -49,7 +75,9 @@ func ast(lxs *lexers) (*tree, *ParseError) {
 		if err != nil {
 			return nil, err
 		}
-		if b != nil {
+		if def, ok := b.(*astFootnoteDef); ok {
+			tr.footnotes = append(tr.footnotes, def)
+		} else if b != nil {
 			tr.blocks = append(tr.blocks, b)
 		}
 		if !lxs.Finished() {
@@ -62,6 +90,12 @@ func ast(lxs *lexers) (*tree, *ParseError) {
 func getBlock(lxs *lexers, newLine bool) (block, *ParseError) {
 	var b block
 	var err *ParseError
+	if newLine && isThematicBreak(lxs.lexers, lxs.current) {
+		return thematicBreak(lxs), nil
+	}
+	if newLine && isDefinitionTerm(lxs.lexers, lxs.current) {
+		return definitionList(lxs)
+	}
 	switch lxs.Current().Type {
 	case lexerHeading:
 		if !newLine {
@@ -87,8 +121,21 @@ func getBlock(lxs *lexers, newLine bool) (block, *ParseError) {
 		} else {
 			b, err = paragraph(lxs, false)
 		}
+	case lexerTable:
+		if newLine {
+			b, err = table(lxs)
+		} else {
+			b, err = paragraph(lxs, false)
+		}
+	case lexerFootnote:
+		if newLine {
+			b, err = footnoteDefinition(lxs)
+		} else {
+			b, err = paragraph(lxs, false)
+		}
 	case lexerCode:
-		if !newLine && len(lxs.Current().Value) == 3 {
+		// in lenient mode, paragraph recovers from it
+		if !newLine && len(lxs.Current().Value) == 3 && lxs.warnings == nil {
 			return nil, &ParseError{lxs: *lxs, internal: ErrInvalidCodeBlockPosition}
 		}
 		if len(lxs.Current().Value) == 1 {
@@ -96,7 +143,31 @@ func getBlock(lxs *lexers, newLine bool) (block, *ParseError) {
 		} else {
 			b, err = code(lxs)
 		}
-	case lexerLiteral, lexerModifier, lexerReplace:
+	case lexerDirective:
+		switch {
+		case strings.HasPrefix(lxs.Current().Value, ":"):
+			b, err = directiveBlock(lxs)
+		case newLine && isShortcodeBlock(lxs):
+			b = shortcode(lxs, true)
+		default:
+			b, err = paragraph(lxs, false)
+		}
+	case lexerMath:
+		if newLine && isMathBlock(lxs) {
+			b, err = math(lxs)
+			if err != nil && lxs.warnings.recover(err) {
+				b, err = &astParagraph{content: []block{astLiteral(lxs.Current().Value)}}, nil
+			}
+		} else {
+			b, err = paragraph(lxs, false)
+		}
+	case lexerHTML:
+		if newLine && isHTMLBlock(lxs) {
+			b = htmlBlock(lxs)
+		} else {
+			b, err = paragraph(lxs, false)
+		}
+	case lexerLiteral, lexerModifier, lexerReplace, lexerWikiLink:
 		b, err = paragraph(lxs, false)
 	case lexerBreak: // do nothing
 	default:
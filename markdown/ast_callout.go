@@ -50,6 +50,22 @@ func (a *astCallout) Eval(opt *Option) (template.HTML, *ParseError) {
 	return callout.Render(), nil
 }
 
+func (a *astCallout) Kind() NodeKind {
+	return KindCallout
+}
+
+// Children returns the title followed by the content of the callout.
+func (a *astCallout) Children() []Node {
+	return append([]Node{a.title}, nodes(a.content)...)
+}
+
+func (a *astCallout) Attr(key string) string {
+	if key == "kind" {
+		return a.kind
+	}
+	return ""
+}
+
 func callout(lxs *lexers) (block, *ParseError) {
 	callout := new(astCallout)
 	if lxs.Current().Value != "[!" {
@@ -33,13 +33,36 @@ func (a *astCode) Eval(_ *Option) (template.HTML, *ParseError) {
 	case codeOneLine:
 		return dom.NewLiteralContentElement("code", content).Render(), nil
 	case codeMultiLine:
-		code := dom.NewContentElement("code", []dom.Element{dom.NewLiteralElement(content)})
-		return dom.NewContentElement("pre", []dom.Element{code}).Render(), nil
+		lang := codeLanguage(a.before)
+		code := dom.NewContentElement("code", []dom.Element{dom.NewLiteralElement(highlight(lang, a.content))})
+		pre := dom.NewContentElement("pre", []dom.Element{code})
+		if len(lang) > 0 {
+			pre.SetAttribute("data-lang", lang)
+		}
+		return pre.Render(), nil
 	default:
 		return "", &ParseError{lxs: lexers{}, internal: ErrUnknownCodeType}
 	}
 }
 
+func (a *astCode) Kind() NodeKind {
+	if a.codeType == codeMultiLine {
+		return KindCodeBlock
+	}
+	return KindCode
+}
+
+func (a *astCode) Children() []Node {
+	return nil
+}
+
+func (a *astCode) Attr(key string) string {
+	if key == "lang" {
+		return codeLanguage(a.before)
+	}
+	return ""
+}
+
 func code(lxs *lexers) (*astCode, *ParseError) {
 	tree := new(astCode)
 	codeTag := lxs.Current().Value
@@ -50,6 +73,7 @@ func code(lxs *lexers) (*astCode, *ParseError) {
 	} else {
 		return nil, &ParseError{lxs: *lxs, internal: ErrInvalidCodeFormat}
 	}
+	start := *lxs
 	started := false
 	for lxs.Next() && lxs.Current().Value != codeTag {
 		isBreak := lxs.Current().Type == lexerBreak
@@ -64,5 +88,9 @@ func code(lxs *lexers) (*astCode, *ParseError) {
 			started = true
 		}
 	}
+	if lxs.Finished() && tree.codeType == codeOneLine {
+		// never closed
+		return nil, &ParseError{lxs: start, internal: ErrInvalidCodeFormat}
+	}
 	return tree, nil
 }
@@ -9,4 +9,32 @@ func TestCode(t *testing.T) {
 		t.Run("mult-line", test("```\n"+"raw\nhehe"+"```", `<pre><code>raw
 hehe</code></pre>`))
 	})
+	t.Run("highlight", func(t *testing.T) {
+		t.Run("injection", test("```go\"><script>x</script>\na\n```", `<pre><code>a
+</code></pre>`))
+		t.Run("unknown", test("```foo\n"+"a <b>\n```", `<pre data-lang="foo"><code>a &lt;b&gt;
+</code></pre>`))
+		t.Run("go", test("```go\n"+`fmt.Println("hey", 42, nil) // comment`+"\n```", `<pre data-lang="go"><code>`+
+			`fmt.<span class="hl-function">Println</span>(<span class="hl-string">&#34;hey&#34;</span>, `+
+			`<span class="hl-number">42</span>, <span class="hl-builtin">nil</span>) <span class="hl-comment">// comment</span>
+</code></pre>`))
+		t.Run("shell", test("```sh\n"+`echo $HOME`+"\n```", `<pre data-lang="sh"><code>`+
+			`<span class="hl-builtin">echo</span> <span class="hl-variable">$HOME</span>
+</code></pre>`))
+		t.Run("toml", test("```toml\n[section]\nname = \"logs\"\n```", `<pre data-lang="toml"><code>`+
+			`<span class="hl-tag">[section]</span>
+<span class="hl-property">name</span> = <span class="hl-string">&#34;logs&#34;</span>
+</code></pre>`))
+		t.Run("html", test("```html\n<a href=\"/\">home</a>\n```", `<pre data-lang="html"><code>`+
+			`<span class="hl-tag">&lt;a</span> <span class="hl-attr">href</span>=<span class="hl-string">&#34;/&#34;</span>`+
+			`<span class="hl-tag">&gt;</span>home<span class="hl-tag">&lt;/a</span><span class="hl-tag">&gt;</span>
+</code></pre>`))
+		t.Run("css", test("```css\na:hover { color: #fff; }\n```", `<pre data-lang="css"><code>`+
+			`a:hover { <span class="hl-property">color</span>: <span class="hl-number">#fff</span>; }
+</code></pre>`))
+		t.Run("json", test("```json\n{\"a\": true}\n```", `<pre data-lang="json"><code>`+
+			`<span class="hl-punct">{</span><span class="hl-property">&#34;a&#34;</span><span class="hl-punct">:</span> `+
+			`<span class="hl-builtin">true</span><span class="hl-punct">}</span>
+</code></pre>`))
+	})
 }
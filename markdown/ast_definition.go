@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"html/template"
+	"strings"
+
+	"anhgelus.world/small-web/dom"
+)
+
+type astDefinitionList struct {
+	items []*astDefinition
+}
+
+func (a *astDefinitionList) Eval(opt *Option) (template.HTML, *ParseError) {
+	list := dom.NewContentElement("dl", make([]dom.Element, 0, len(a.items)))
+	for _, c := range a.items {
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		list.Contents = append(list.Contents, dom.NewLiteralElement(ct))
+	}
+	return list.Render(), nil
+}
+
+func (a *astDefinitionList) Kind() NodeKind {
+	return KindDefinitionList
+}
+
+func (a *astDefinitionList) Children() []Node {
+	return nodes(a.items)
+}
+
+func (a *astDefinitionList) Attr(string) string {
+	return ""
+}
+
+// astDefinition is a term or one of its definitions.
+type astDefinition struct {
+	term    bool
+	content *astParagraph
+}
+
+func (a *astDefinition) Eval(opt *Option) (template.HTML, *ParseError) {
+	content, err := a.content.Eval(opt)
+	if err != nil {
+		return "", err
+	}
+	tag := "dd"
+	if a.term {
+		tag = "dt"
+	}
+	return dom.NewLiteralContentElement(tag, template.HTML(strings.TrimSpace(string(content)))).Render(), nil
+}
+
+func (a *astDefinition) Kind() NodeKind {
+	if a.term {
+		return KindDefinitionTerm
+	}
+	return KindDefinitionDescription
+}
+
+func (a *astDefinition) Children() []Node {
+	return a.content.Children()
+}
+
+func (a *astDefinition) Attr(string) string {
+	return ""
+}
+
+func definitionList(lxs *lexers) (*astDefinitionList, *ParseError) {
+	tree := new(astDefinitionList)
+	l := lxs.lexers
+	i := lxs.current
+	for {
+		// i is the first lexer of the term
+		j := lineEnd(l, i)
+		term, err := definition(lxs, l[i:j], true)
+		if err != nil {
+			return nil, err
+		}
+		tree.items = append(tree.items, term)
+		// j is the break ending the previous line
+		for j < len(l) && len(l[j].Value) == 1 && isDefinition(l, j+1) {
+			tokens := trimDefinitionMarker(l[j+1 : lineEnd(l, j+1)])
+			j = lineEnd(l, j+1)
+			// indented lines continue the definition
+			for j < len(l)-1 && len(l[j].Value) == 1 && indentation(l[j+1]) > 0 && !isBlankLine(l, j+1) {
+				tokens = append(tokens, l[j])
+				tokens = append(tokens, dedent(l[j+1:lineEnd(l, j+1)], indentation(l[j+1]))...)
+				j = lineEnd(l, j+1)
+			}
+			desc, err := definition(lxs, tokens, false)
+			if err != nil {
+				return nil, err
+			}
+			tree.items = append(tree.items, desc)
+		}
+		if j >= len(l)-1 || !isDefinitionTerm(l, j+1) {
+			// the break ending the list is parsed after
+			lxs.current = j - 1
+			return tree, nil
+		}
+		i = j + 1
+	}
+}
+
+func definition(lxs *lexers, tokens []lexer, term bool) (*astDefinition, *ParseError) {
+	// a definition can be on several lines
+	p, err := paragraph(&lexers{lexers: tokens, warnings: lxs.warnings}, false)
+	if err != nil {
+		return nil, err
+	}
+	p.oneLine = true
+	return &astDefinition{term: term, content: p}, nil
+}
+
+// isDefinitionTerm returns true if the line starting at i is followed by a definition.
+func isDefinitionTerm(l []lexer, i int) bool {
+	if i >= len(l) || !isLazyContinuation(l, i) || isDefinition(l, i) || isBlankLine(l, i) {
+		return false
+	}
+	j := lineEnd(l, i)
+	return j < len(l) && len(l[j].Value) == 1 && isDefinition(l, j+1)
+}
+
+// isDefinition returns true if the line starting at i starts with ": ".
+func isDefinition(l []lexer, i int) bool {
+	return i < len(l) && l[i].Type == lexerLiteral && strings.HasPrefix(l[i].Value, ": ")
+}
+
+func trimDefinitionMarker(line []lexer) []lexer {
+	lx := line[0]
+	v := strings.TrimLeft(lx.Value[1:], " \t")
+	res := make([]lexer, 0, len(line))
+	if len(v) > 0 {
+		lx.Column += len([]rune(lx.Value)) - len([]rune(v))
+		lx.Value = v
+		res = append(res, lx)
+	}
+	return append(res, line[1:]...)
+}
@@ -0,0 +1,37 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+var rwDefinition = `
+Markdown
+: un langage de balisage
+: créé par John Gruber
+
+HTML
+: le langage du *web*
+  sur plusieurs lignes
+`
+
+var expectedDefinition = `
+<dl>
+<dt>Markdown</dt>
+<dd>un langage de balisage</dd>
+<dd>créé par John Gruber</dd>
+<dt>HTML</dt>
+<dd>le langage du <em>web</em> sur plusieurs lignes</dd>
+</dl>
+`
+
+func TestDefinitionList(t *testing.T) {
+	t.Run("definitions", func(t *testing.T) {
+		t.Run("combo", test(rwDefinition, strings.ReplaceAll(expectedDefinition, "\n", "")))
+		t.Run("after paragraph", test("Un paragraphe\nTerme\n: définition",
+			`<p>Un paragraphe</p><dl><dt>Terme</dt><dd>définition</dd></dl>`))
+		t.Run("end", test("Terme\n: définition\n\nparagraphe",
+			`<dl><dt>Terme</dt><dd>définition</dd></dl><p>paragraphe</p>`))
+		t.Run("not definition", test("Terme\n:pas une définition", `<p>Terme :pas une définition</p>`))
+	})
+}
@@ -0,0 +1,233 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidDirective  = errors.New("invalid directive")
+	ErrUnknownDirective  = errors.Join(ErrInvalidDirective, errors.New("unknown directive"))
+	ErrUnclosedDirective = errors.Join(ErrInvalidDirective, errors.New("unclosed directive"))
+)
+
+// Directive renders a directive registered in Option.Directives.
+//
+// Directives are written {{< name args >}}, or with a markdown body:
+//
+//	::: name args
+//	body
+//	:::
+type Directive func(args *DirectiveArgs) (template.HTML, error)
+
+// DirectiveArgs are the arguments given to a Directive.
+type DirectiveArgs struct {
+	Name string
+	// Args are the positional arguments.
+	Args []string
+	// Named are the arguments written key=value.
+	Named map[string]string
+	// Body is the rendered body of a block directive.
+	Body template.HTML
+	// Block is true if the directive is alone on its line or if it has a body.
+	Block bool
+	// Option used to render the document.
+	Option *Option
+}
+
+type astDirective struct {
+	source  string
+	name    string
+	args    []string
+	named   map[string]string
+	block   bool
+	content []block
+	lxs     lexers
+}
+
+func (a *astDirective) Eval(opt *Option) (template.HTML, *ParseError) {
+	fn, ok := opt.Directives[a.name]
+	if !ok {
+		err := &ParseError{lxs: a.lxs, internal: errors.Join(ErrUnknownDirective, fmt.Errorf("directive: %s", a.name))}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		return template.HTML(template.HTMLEscapeString(a.source)), nil
+	}
+	var body template.HTML
+	for _, c := range a.content {
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		body += ct
+	}
+	res, e := fn(&DirectiveArgs{
+		Name:   a.name,
+		Args:   a.args,
+		Named:  a.named,
+		Body:   body,
+		Block:  a.block,
+		Option: opt,
+	})
+	if e != nil {
+		err := &ParseError{lxs: a.lxs, internal: errors.Join(ErrInvalidDirective, e)}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		return template.HTML(template.HTMLEscapeString(a.source)), nil
+	}
+	return res, nil
+}
+
+func (a *astDirective) Kind() NodeKind {
+	return KindDirective
+}
+
+func (a *astDirective) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astDirective) Attr(key string) string {
+	if key == "name" {
+		return a.name
+	}
+	return ""
+}
+
+// shortcode parses the directive {{< name args >}}.
+func shortcode(lxs *lexers, block bool) *astDirective {
+	v := lxs.Current().Value
+	tree := &astDirective{source: v, block: block, lxs: *lxs}
+	tree.name, tree.args, tree.named = directiveArgs(v[3 : len(v)-3])
+	return tree
+}
+
+// directiveBlock parses the directive starting with the fence ::: name args and ending with the fence :::.
+func directiveBlock(lxs *lexers) (block, *ParseError) {
+	open := lxs.Current().Value
+	tree := &astDirective{source: open, block: true, lxs: *lxs}
+	tree.name, tree.args, tree.named = directiveArgs(strings.TrimLeft(open, ":"))
+	if len(tree.name) == 0 {
+		err := &ParseError{lxs: *lxs, internal: errors.Join(ErrInvalidDirective, errors.New("closing fence without opening"))}
+		if !lxs.warnings.recover(err) {
+			return nil, err
+		}
+		return paragraph(lxs, false)
+	}
+	l := lxs.lexers
+	depth := 0
+	for i := lxs.current + 1; i < len(l); i++ {
+		if l[i].Type != lexerDirective || !strings.HasPrefix(l[i].Value, ":") {
+			continue
+		}
+		if name, _, _ := directiveArgs(strings.TrimLeft(l[i].Value, ":")); len(name) > 0 {
+			depth++
+			continue
+		}
+		if depth > 0 {
+			depth--
+			continue
+		}
+		tokens := make([]lexer, i-lxs.current-1)
+		copy(tokens, l[lxs.current+1:i])
+		tr, err := ast(&lexers{current: -1, lexers: tokens, warnings: lxs.warnings})
+		if err != nil {
+			return nil, err
+		}
+		tree.content = tr.blocks
+		lxs.current = i
+		return tree, nil
+	}
+	err := &ParseError{lxs: *lxs, internal: errors.Join(ErrUnclosedDirective, fmt.Errorf("directive: %s", tree.name))}
+	if !lxs.warnings.recover(err) {
+		return nil, err
+	}
+	return paragraph(lxs, false)
+}
+
+// isShortcodeBlock returns true if the current lexer is a shortcode alone on its line.
+func isShortcodeBlock(lxs *lexers) bool {
+	next := lxs.current + 1
+	return strings.HasPrefix(lxs.Current().Value, "{{<") &&
+		(next >= len(lxs.lexers) || lxs.lexers[next].Type == lexerBreak)
+}
+
+// directiveArgs splits the name and the arguments of a directive.
+// Arguments are separated by spaces, and they can be quoted.
+func directiveArgs(s string) (string, []string, map[string]string) {
+	var fields []string
+	var sb strings.Builder
+	quoted, started := false, false
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(c) && !quoted:
+			if started {
+				fields = append(fields, sb.String())
+				sb.Reset()
+				started = false
+			}
+		default:
+			sb.WriteRune(c)
+			started = true
+		}
+	}
+	if started {
+		fields = append(fields, sb.String())
+	}
+	if len(fields) == 0 {
+		return "", nil, nil
+	}
+	var args []string
+	named := make(map[string]string)
+	for _, f := range fields[1:] {
+		if k, v, ok := strings.Cut(f, "="); ok && isDirectiveKey(k) {
+			named[k] = v
+		} else {
+			args = append(args, f)
+		}
+	}
+	return fields[0], args, named
+}
+
+func isDirectiveKey(k string) bool {
+	for _, c := range k {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return len(k) > 0
+}
+
+// shortcodeLength returns the length of the shortcode starting at i.
+// It returns 0 if it is not closed on the same line.
+func shortcodeLength(runes []rune, i int) int {
+	if !strings.HasPrefix(string(runes[i:min(i+3, len(runes))]), "{{<") {
+		return 0
+	}
+	for j := i + 3; j < len(runes)-2 && runes[j] != '\n'; j++ {
+		if runes[j] == '>' && runes[j+1] == '}' && runes[j+2] == '}' {
+			return j + 3 - i
+		}
+	}
+	return 0
+}
+
+// fenceLength returns the length of the directive fence starting at i, until the end of the line.
+// It returns 0 if there are less than three colons.
+func fenceLength(runes []rune, i int) int {
+	if runLength(runes, i) < 3 {
+		return 0
+	}
+	j := i
+	for j < len(runes) && runes[j] != '\n' {
+		j++
+	}
+	return j - i
+}
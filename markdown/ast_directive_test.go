@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func directiveOption() *Option {
+	return &Option{Directives: map[string]Directive{
+		"youtube": func(args *DirectiveArgs) (template.HTML, error) {
+			if len(args.Args) != 1 {
+				return "", errors.New("missing id")
+			}
+			return template.HTML(fmt.Sprintf(`<iframe src="https://www.youtube-nocookie.com/embed/%s" title="%s"></iframe>`,
+				template.HTMLEscapeString(args.Args[0]), template.HTMLEscapeString(args.Named["title"]))), nil
+		},
+		"kbd": func(args *DirectiveArgs) (template.HTML, error) {
+			return template.HTML("<kbd>" + template.HTMLEscapeString(strings.Join(args.Args, "+")) + "</kbd>"), nil
+		},
+		"box": func(args *DirectiveArgs) (template.HTML, error) {
+			return template.HTML(`<div class="` + strings.Join(args.Args, " ") + `">` + string(args.Body) + `</div>`), nil
+		},
+	}}
+}
+
+func TestDirective(t *testing.T) {
+	t.Run("shortcode", func(t *testing.T) {
+		t.Run("block", testWithOptions(directiveOption(), `{{< youtube abc title="Une vidéo" >}}`,
+			`<iframe src="https://www.youtube-nocookie.com/embed/abc" title="Une vidéo"></iframe>`))
+		t.Run("inline", testWithOptions(directiveOption(), `Appuyez sur {{< kbd Ctrl C >}} !`,
+			`<p>Appuyez sur <kbd>Ctrl+C</kbd> !</p>`))
+		t.Run("modifier", testWithOptions(directiveOption(), `**{{< kbd Ctrl >}}**`, `<p><b><kbd>Ctrl</kbd></b></p>`))
+		t.Run("disabled", test(`{{< kbd Ctrl >}}`, `<p>{{&lt; kbd Ctrl &gt;}}</p>`))
+	})
+	t.Run("block", func(t *testing.T) {
+		t.Run("simple", testWithOptions(directiveOption(), "::: box note\nDu *texte*\n:::",
+			`<div class="note"><p>Du <em>texte</em></p></div>`))
+		t.Run("nested", testWithOptions(directiveOption(), "::: box a\n::: box b\nb\n:::\n\na\n:::\n\nfin",
+			`<div class="a"><div class="b"><p>b</p></div><p>a</p></div><p>fin</p>`))
+		t.Run("after paragraph", testWithOptions(directiveOption(), "avant\n::: box\napres\n:::",
+			`<p>avant</p><div class=""><p>apres</p></div>`))
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, s := range []string{`{{< inconnu >}}`, `{{< youtube >}}`, "::: box\nnon fermé", ":::"} {
+			if _, err := Parse(s, directiveOption()); err == nil {
+				t.Errorf("expected error for %s", s)
+			}
+		}
+		opt := directiveOption()
+		opt.Lenient = true
+		doc, err := ParseDocument("{{< inconnu >}}", opt)
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		res, err := doc.HTML()
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		if string(res) != `{{&lt; inconnu &gt;}}` || len(doc.Warnings()) != 1 {
+			t.Errorf("invalid value, got %s %v", res, doc.Warnings())
+		}
+	})
+}
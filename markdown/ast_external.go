@@ -7,7 +7,8 @@ import (
 	"anhgelus.world/small-web/dom"
 )
 
-var ExternalLink = regexp.MustCompile(`https?://`)
+// ExternalLink matches the URLs of remote resources.
+var ExternalLink = regexp.MustCompile(`^https?://`)
 
 type astLink struct {
 	content block
@@ -15,7 +16,7 @@ type astLink struct {
 }
 
 func (a *astLink) Eval(opt *Option) (template.HTML, *ParseError) {
-	content, err := a.content.Eval(opt)
+	content, err := evalInline([]block{a.content}, opt)
 	if err != nil {
 		return "", err
 	}
@@ -26,6 +27,21 @@ func (a *astLink) Eval(opt *Option) (template.HTML, *ParseError) {
 	return opt.RenderLink(string(content), string(href)), nil
 }
 
+func (a *astLink) Kind() NodeKind {
+	return KindLink
+}
+
+func (a *astLink) Children() []Node {
+	return []Node{a.content}
+}
+
+func (a *astLink) Attr(key string) string {
+	if key == "href" {
+		return literal(a.href)
+	}
+	return ""
+}
+
 func RenderLink(content, href string) template.HTML {
 	anchor := dom.NewLiteralContentElement("a", template.HTML(content))
 	anchor.SetAttribute("href", href)
@@ -50,8 +66,12 @@ func (a *astImage) Eval(opt *Option) (template.HTML, *ParseError) {
 	if err != nil {
 		return "", err
 	}
+	var info *dom.ResponsiveImg
+	if opt.ResponsiveImage != nil {
+		info = opt.ResponsiveImage(string(src))
+	}
 	src = template.HTML(opt.ImageSource(string(src)))
-	img := dom.NewImg(src, alt)
+	img := dom.NewResponsiveImg(dom.NewImg(src, alt), info, opt.ImageSizes)
 	figure := dom.NewContentElement("figure", []dom.Element{img})
 	if a.source == nil {
 		return figure.Render(), nil
@@ -70,6 +90,26 @@ func (a *astImage) Eval(opt *Option) (template.HTML, *ParseError) {
 	return figure.Render(), nil
 }
 
+func (a *astImage) Kind() NodeKind {
+	return KindImage
+}
+
+// Children returns the source of the image.
+func (a *astImage) Children() []Node {
+	return nodes(a.source)
+}
+
+func (a *astImage) Attr(key string) string {
+	switch key {
+	case "src":
+		return literal(a.src)
+	case "alt":
+		return literal(a.alt)
+	default:
+		return ""
+	}
+}
+
 func external(lxs *lexers) (block, *ParseError) {
 	tp := lxs.Current().Value
 	if !lxs.Next() {
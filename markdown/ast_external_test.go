@@ -1,14 +1,20 @@
 package markdown
 
-import "testing"
+import (
+	"testing"
+
+	"anhgelus.world/small-web/dom"
+)
 
 func TestExternal(t *testing.T) {
 	t.Run("link", func(t *testing.T) {
 		t.Run("simple", test("[content](href)", `<p><a href="href">content</a></p>`))
 		t.Run("combo", test("Hey, [link](href)", `<p>Hey, <a href="href">link</a></p>`))
+		t.Run("external", test("[a](http://example.org) [b](/r?to=https://example.org)",
+			`<p><a href="http://example.org" target="_blank" rel="noreferer">a</a> <a href="/r?to=https://example.org">b</a></p>`))
 	})
 	t.Run("image", func(t *testing.T) {
-		t.Run("simple", test("![image alt](image src)", `<figure><img alt="image alt" src="image src"></figure>`))
+		t.Run("simple", test("![image alt](image src)", `<figure><img alt="image alt" src="image src" loading="lazy"></figure>`))
 		t.Run("combo", test(`
 Avant la source
 ![image alt](image src)
@@ -16,6 +22,31 @@ source 1
 source 2
 
 Hors de la source
-`, `<p>Avant la source</p><figure><img alt="image alt" src="image src"><figcaption>source 1 source 2</figcaption></figure><p>Hors de la source</p>`))
+`, `<p>Avant la source</p><figure><img alt="image alt" src="image src" loading="lazy"><figcaption>source 1 source 2</figcaption></figure><p>Hors de la source</p>`))
 	})
 }
+
+func TestResponsiveImage(t *testing.T) {
+	opt := &Option{
+		ImageSource: func(s string) string { return "/static/" + s },
+		ResponsiveImage: func(s string) *dom.ResponsiveImg {
+			if s != "pfp.jpg" {
+				return nil
+			}
+			return &dom.ResponsiveImg{Width: 960, Height: 480, Srcsets: []dom.Srcset{{
+				Candidates: []dom.ImgCandidate{{Src: "/images/pfp-480.jpg", Width: 480}, {Src: "/static/pfp.jpg", Width: 960}},
+			}}}
+		},
+		ImageSizes: "100vw",
+	}
+	res, err := Parse("![Alt](pfp.jpg)\n\n![Autre](autre.jpg)", opt)
+	if err != nil {
+		t.Fatal(err.Pretty())
+	}
+	expected := `<figure><img alt="Alt" src="/static/pfp.jpg" loading="lazy" width="960" height="480" ` +
+		`srcset="/images/pfp-480.jpg 480w, /static/pfp.jpg 960w" sizes="100vw"></figure>` +
+		`<figure><img alt="Autre" src="/static/autre.jpg" loading="lazy"></figure>`
+	if string(res) != expected {
+		t.Errorf("invalid value, got %s", res)
+	}
+}
@@ -0,0 +1,235 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"anhgelus.world/small-web/dom"
+)
+
+var (
+	ErrInvalidFootnote   = errors.New("invalid footnote")
+	ErrUnknownFootnote   = errors.Join(ErrInvalidFootnote, errors.New("unknown footnote"))
+	ErrUnusedFootnote    = errors.Join(ErrInvalidFootnote, errors.New("unused footnote"))
+	ErrDuplicateFootnote = errors.Join(ErrInvalidFootnote, errors.New("duplicate footnote definition"))
+)
+
+// footnotes stores the state of footnotes during the evaluation of a tree.
+type footnotes struct {
+	defs map[string]*astFootnoteDef
+	// list of the definitions in source order
+	list []*astFootnoteDef
+	// order of the first reference of each footnote
+	order []string
+	// refs is the number of references for each footnote
+	refs map[string]int
+}
+
+func newFootnotes(defs []*astFootnoteDef, w *warnings) (*footnotes, *ParseError) {
+	fn := &footnotes{
+		defs: make(map[string]*astFootnoteDef, len(defs)),
+		refs: make(map[string]int, len(defs)),
+	}
+	for _, d := range defs {
+		if _, ok := fn.defs[d.id]; ok {
+			err := &ParseError{lxs: d.lxs, internal: ErrDuplicateFootnote}
+			if !w.recover(err) {
+				return nil, err
+			}
+			continue
+		}
+		fn.defs[d.id] = d
+		fn.list = append(fn.list, d)
+	}
+	return fn, nil
+}
+
+func (f *footnotes) Eval(opt *Option) (template.HTML, *ParseError) {
+	for _, d := range f.list {
+		if f.refs[d.id] == 0 {
+			err := &ParseError{
+				lxs:      d.lxs,
+				internal: errors.Join(ErrUnusedFootnote, fmt.Errorf("footnote: %s", d.id)),
+			}
+			if !opt.warnings.recover(err) {
+				return "", err
+			}
+		}
+	}
+	if len(f.order) == 0 {
+		return "", nil
+	}
+	list := dom.NewContentElement("ol", make([]dom.Element, 0, len(f.order)))
+	for _, id := range f.order {
+		content, err := f.defs[id].content.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		content = template.HTML(strings.TrimSpace(string(content)))
+		for i := range f.refs[id] {
+			label := "↩"
+			if i > 0 {
+				label += fmt.Sprintf("%d", i+1)
+			}
+			back := dom.NewLiteralContentElement("a", template.HTML(label))
+			back.SetAttribute("href", "#"+footnoteRefAnchor(id, i+1))
+			back.ClassList().Add("footnote-back")
+			content += " " + back.Render()
+		}
+		item := dom.NewContentElement("li", []dom.Element{dom.NewParagraph(content)})
+		item.SetAttribute("id", footnoteAnchor(id))
+		list.Contents = append(list.Contents, item)
+	}
+	section := dom.NewContentElement("section", []dom.Element{list})
+	section.ClassList().Add("footnotes")
+	return section.Render(), nil
+}
+
+type astFootnoteRef struct {
+	id  string
+	lxs lexers
+}
+
+func (a *astFootnoteRef) Eval(opt *Option) (template.HTML, *ParseError) {
+	f := opt.footnotes
+	if f == nil || f.defs[a.id] == nil {
+		err := &ParseError{
+			lxs:      a.lxs,
+			internal: errors.Join(ErrUnknownFootnote, fmt.Errorf("footnote: %s", a.id)),
+		}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		return astLiteral("[^" + a.id + "]").Eval(opt)
+	}
+	if f.refs[a.id] == 0 {
+		f.order = append(f.order, a.id)
+	}
+	f.refs[a.id]++
+	n := 0
+	for n < len(f.order) && f.order[n] != a.id {
+		n++
+	}
+	anchor := dom.NewLiteralContentElement("a", template.HTML(fmt.Sprintf("%d", n+1)))
+	anchor.SetAttribute("href", "#"+footnoteAnchor(a.id))
+	sup := dom.NewContentElement("sup", []dom.Element{anchor})
+	sup.SetAttribute("id", footnoteRefAnchor(a.id, f.refs[a.id]))
+	sup.ClassList().Add("footnote-ref")
+	return sup.Render(), nil
+}
+
+func (a *astFootnoteRef) Kind() NodeKind {
+	return KindFootnoteRef
+}
+
+func (a *astFootnoteRef) Children() []Node {
+	return nil
+}
+
+func (a *astFootnoteRef) Attr(key string) string {
+	if key == "id" {
+		return a.id
+	}
+	return ""
+}
+
+type astFootnoteDef struct {
+	id      string
+	content *astParagraph
+	lxs     lexers
+}
+
+func (a *astFootnoteDef) Eval(_ *Option) (template.HTML, *ParseError) {
+	// definitions are rendered by footnotes
+	return "", nil
+}
+
+func (a *astFootnoteDef) Kind() NodeKind {
+	return KindFootnote
+}
+
+func (a *astFootnoteDef) Children() []Node {
+	return a.content.Children()
+}
+
+func (a *astFootnoteDef) Attr(key string) string {
+	if key == "id" {
+		return a.id
+	}
+	return ""
+}
+
+func footnoteAnchor(id string) string {
+	return "fn-" + footnoteSlug(id)
+}
+
+func footnoteRefAnchor(id string, n int) string {
+	if n <= 1 {
+		return "fnref-" + footnoteSlug(id)
+	}
+	return fmt.Sprintf("fnref-%s-%d", footnoteSlug(id), n)
+}
+
+func footnoteSlug(id string) string {
+	return template.HTMLEscapeString(strings.Join(strings.Fields(id), "-"))
+}
+
+// footnoteID reads the id of the footnote starting at the current lexer.
+// When it returns true, the current lexer is the closing bracket.
+func footnoteID(lxs *lexers) (string, bool) {
+	var id string
+	for lxs.Next() {
+		current := lxs.Current()
+		switch {
+		case current.Type == lexerBreak:
+			return "", false
+		case current.Value == "]":
+			return strings.TrimSpace(id), len(strings.TrimSpace(id)) > 0
+		default:
+			id += current.Value
+		}
+	}
+	return "", false
+}
+
+func isFootnoteDefinition(lxs *lexers) bool {
+	l := *lxs
+	_, ok := footnoteID(&l)
+	return ok && l.Next() && strings.HasPrefix(l.Current().Value, ":")
+}
+
+func footnoteRef(lxs *lexers) block {
+	start := lxs.current
+	ref := &astFootnoteRef{lxs: *lxs}
+	var ok bool
+	ref.id, ok = footnoteID(lxs)
+	if !ok {
+		return reset(lxs, start)
+	}
+	return ref
+}
+
+func footnoteDefinition(lxs *lexers) (block, *ParseError) {
+	if !isFootnoteDefinition(lxs) {
+		return paragraph(lxs, false)
+	}
+	def := &astFootnoteDef{lxs: *lxs}
+	def.id, _ = footnoteID(lxs)
+	lxs.Next()
+	var err *ParseError
+	def.content, err = paragraph(lxs, false)
+	if err != nil {
+		return nil, err
+	}
+	def.content.oneLine = true
+	// removing the colon
+	if len(def.content.content) == 0 {
+		return def, nil
+	}
+	if v, ok := def.content.content[0].(astLiteral); ok {
+		def.content.content[0] = astLiteral(strings.TrimPrefix(string(v), ":"))
+	}
+	return def, nil
+}
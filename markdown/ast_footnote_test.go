@@ -0,0 +1,53 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var rawFootnote = `
+Texte[^1] et **gras[^note]** puis encore[^1].
+
+[^1]: Première note
+sur deux lignes.
+[^note]: Note en *italique*.
+`
+
+var expectedFootnote = `
+<p>Texte<sup id="fnref-1" class="footnote-ref"><a href="#fn-1">1</a></sup>
+ et <b>gras<sup id="fnref-note" class="footnote-ref"><a href="#fn-note">2</a></sup></b>
+ puis encore<sup id="fnref-1-2" class="footnote-ref"><a href="#fn-1">1</a></sup>.</p>
+<section class="footnotes"><ol>
+<li id="fn-1"><p>Première note sur deux lignes.
+ <a href="#fnref-1" class="footnote-back">↩</a> <a href="#fnref-1-2" class="footnote-back">↩2</a></p></li>
+<li id="fn-note"><p>Note en <em>italique</em>. <a href="#fnref-note" class="footnote-back">↩</a></p></li>
+</ol></section>
+`
+
+func TestFootnote(t *testing.T) {
+	t.Run("footnote", func(t *testing.T) {
+		t.Run("simple", test("a[^x]\n\n[^x]: b", `<p>a<sup id="fnref-x" class="footnote-ref"><a href="#fn-x">1</a></sup></p>`+
+			`<section class="footnotes"><ol><li id="fn-x"><p>b <a href="#fnref-x" class="footnote-back">↩</a></p></li></ol></section>`))
+		t.Run("combo", test(rawFootnote, strings.ReplaceAll(expectedFootnote, "\n", "")))
+		t.Run("not_closed", test("a [^ b", `<p>a [^ b</p>`))
+	})
+	t.Run("errors", func(t *testing.T) {
+		fn := func(input string, expected error) func(*testing.T) {
+			return func(t *testing.T) {
+				t.Parallel()
+				v, err := Parse(input, nil)
+				if err == nil {
+					t.Fatalf("expected error, got %s", v)
+				}
+				if !errors.Is(err.internal, expected) {
+					t.Errorf("invalid error, got %v", err)
+				}
+				t.Log(err.Pretty())
+			}
+		}
+		t.Run("unknown", fn("a[^x]", ErrUnknownFootnote))
+		t.Run("unused", fn("a\n\n[^x]: b", ErrUnusedFootnote))
+		t.Run("duplicate", fn("a[^x]\n\n[^x]: b\n[^x]: c", ErrDuplicateFootnote))
+	})
+}
@@ -3,6 +3,7 @@ package markdown
 import (
 	"errors"
 	"html/template"
+	"strconv"
 	"strings"
 
 	"anhgelus.world/small-web/dom"
@@ -16,23 +17,36 @@ type astHeading struct {
 }
 
 func (a *astHeading) Eval(opt *Option) (template.HTML, *ParseError) {
-	if a.level > 6 {
-		return "", &ParseError{lxs: lexers{}, internal: ErrInvalidHeader}
-	}
 	var content template.HTML
 	content, err := a.content.Eval(opt)
 	if err != nil {
 		return "", err
 	}
-	return dom.NewHeading(
-		a.level,
-		template.HTML(strings.TrimSpace(string(content))),
-	).Render(), nil
+	content = template.HTML(strings.TrimSpace(string(content)))
+	h := opt.outline.add(a.level, content)
+	heading := dom.NewHeading(a.level, content)
+	heading.SetAttribute("id", h.ID)
+	return heading.Render(), nil
+}
+
+func (a *astHeading) Kind() NodeKind {
+	return KindHeading
+}
+
+func (a *astHeading) Children() []Node {
+	return a.content.Children()
+}
+
+func (a *astHeading) Attr(key string) string {
+	if key == "level" {
+		return strconv.Itoa(int(a.level))
+	}
+	return ""
 }
 
 func heading(lxs *lexers) (*astHeading, *ParseError) {
 	b := &astHeading{level: uint(len(lxs.Current().Value))}
-	if !lxs.Next() {
+	if b.level > 6 || !lxs.Next() {
 		return nil, &ParseError{lxs: *lxs, internal: ErrInvalidHeader}
 	}
 	var err *ParseError
@@ -0,0 +1,57 @@
+package markdown
+
+import "testing"
+
+func TestHeading(t *testing.T) {
+	t.Run("heading", func(t *testing.T) {
+		t.Run("simple", test("# Bonsoir", `<h1 id="bonsoir">Bonsoir</h1>`))
+		t.Run("accents", test("## Été à Noël, ça va ?", `<h2 id="ete-a-noel-ca-va">Été à Noël, ça va ?</h2>`))
+		t.Run("modifier", test("# Du **gras**", `<h1 id="du-gras">Du <b>gras</b></h1>`))
+		t.Run("unique", test("# Titre\n# Titre", `<h1 id="titre">Titre</h1><h1 id="titre-2">Titre</h1>`))
+	})
+}
+
+func TestOutline(t *testing.T) {
+	_, outline, err := ParseWithOutline("# Un\n## Deux\n### Trois\n## Quatre\n# Cinq", nil)
+	if err != nil {
+		t.Fatal(err.Pretty())
+	}
+	if len(outline) != 5 {
+		t.Fatalf("invalid outline length, got %d", len(outline))
+	}
+	if outline[2].Level != 3 || outline[2].ID != "trois" || outline[2].Title != "Trois" {
+		t.Errorf("invalid heading, got %+v", outline[2])
+	}
+	expected := `<nav class="toc"><ol><li><a href="#un">Un</a><ol><li><a href="#deux">Deux</a><ol>` +
+		`<li><a href="#trois">Trois</a></li></ol></li><li><a href="#quatre">Quatre</a></li></ol></li>` +
+		`<li><a href="#cinq">Cinq</a></li></ol></nav>`
+	if got := string(outline.Render()); got != expected {
+		t.Errorf("invalid render, got %s", got)
+	}
+	doc, err := ParseDocument("# Un\n# Un", nil)
+	if err != nil {
+		t.Fatal(err.Pretty())
+	}
+	if _, err = doc.HTML(); err != nil {
+		t.Fatal(err.Pretty())
+	}
+	if outline = doc.Outline(); len(outline) != 2 || outline[1].ID != "un-2" {
+		t.Errorf("invalid document outline, got %+v", outline)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	fn := func(s, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			if got := Slugify(s); got != expected {
+				t.Errorf("invalid slug, got %s", got)
+			}
+		}
+	}
+	t.Run("slugify", func(t *testing.T) {
+		t.Run("simple", fn("Bonsoir", "bonsoir"))
+		t.Run("spaces", fn("  Hello   world !  ", "hello-world"))
+		t.Run("french", fn("Œuvre déjà créée à Noël", "oeuvre-deja-creee-a-noel"))
+	})
+}
@@ -0,0 +1,369 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"slices"
+	"strings"
+	"unicode"
+
+	"anhgelus.world/small-web/dom"
+)
+
+var (
+	ErrInvalidHTML    = errors.New("invalid HTML")
+	ErrUnclosedHTML   = errors.Join(ErrInvalidHTML, errors.New("unclosed element"))
+	ErrUnexpectedHTML = errors.Join(ErrInvalidHTML, errors.New("unexpected closing tag"))
+)
+
+// DefaultHTML is an allowlist of raw HTML elements safe to embed in a document.
+var DefaultHTML = map[string][]string{
+	"abbr":    {"title"},
+	"audio":   {"src", "controls", "loop", "muted", "preload"},
+	"br":      nil,
+	"cite":    nil,
+	"details": {"open"},
+	"dfn":     {"title"},
+	"ins":     nil,
+	"kbd":     nil,
+	"q":       {"cite"},
+	"samp":    nil,
+	"small":   nil,
+	"source":  {"src", "type"},
+	"span":    {"lang", "title"},
+	"summary": nil,
+	"time":    {"datetime"},
+	"track":   {"src", "kind", "srclang", "label", "default"},
+	"u":       nil,
+	"var":     nil,
+	"video":   {"src", "controls", "loop", "muted", "preload", "poster", "width", "height"},
+	"wbr":     nil,
+}
+
+// htmlBlocks are the elements starting an HTML block when they are at the start of a line.
+var htmlBlocks = map[string]bool{
+	"audio":   true,
+	"details": true,
+	"summary": true,
+	"video":   true,
+}
+
+// htmlVoids are the elements without closing tag.
+var htmlVoids = map[string]bool{
+	"br":     true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// htmlURLs are the attributes containing an URL.
+// src and poster are modified by Option.ImageSource, like images.
+var htmlURLs = map[string]bool{
+	"cite":   true,
+	"poster": true,
+	"src":    true,
+}
+
+type htmlTag struct {
+	name    string
+	closing bool
+	attrs   [][2]string
+}
+
+// astHTML is an allowed HTML tag.
+// Its attributes are sanitized during the evaluation.
+type astHTML struct {
+	raw string
+	lxs lexers
+}
+
+func (a *astHTML) Eval(opt *Option) (template.HTML, *ParseError) {
+	tag, _ := parseHTMLTag([]rune(a.raw), 0)
+	allowed, ok := opt.HTML[tag.name]
+	if !ok {
+		return template.HTML(template.HTMLEscapeString(a.raw)), nil
+	}
+	if tag.closing {
+		if htmlVoids[tag.name] {
+			return "", nil
+		}
+		return opt.html.close(a, tag.name, opt)
+	}
+	el := dom.NewVoidElement(tag.name)
+	for _, attr := range tag.attrs {
+		k, v := attr[0], html.UnescapeString(attr[1])
+		if !slices.Contains(allowed, k) || el.HasAttribute(k) {
+			continue
+		}
+		if htmlURLs[k] {
+			if !safeURL(v) {
+				continue
+			}
+			if k != "cite" {
+				v = opt.ImageSource(v)
+			}
+		}
+		el.SetAttribute(k, html.EscapeString(v))
+	}
+	if !htmlVoids[tag.name] {
+		opt.html.push(a, tag.name)
+	}
+	return el.Render(), nil
+}
+
+func (a *astHTML) Kind() NodeKind {
+	return KindHTML
+}
+
+func (a *astHTML) Children() []Node {
+	return nil
+}
+
+func (a *astHTML) Attr(key string) string {
+	if key == "tag" {
+		tag, _ := parseHTMLTag([]rune(a.raw), 0)
+		return tag.name
+	}
+	return ""
+}
+
+// htmlStack contains the raw HTML elements opened during the evaluation.
+// Elements opened in a scope must be closed in it: inline elements are closed in their paragraph, and elements of
+// HTML blocks are closed in the document.
+type htmlStack struct {
+	open []*astHTML
+	// names of the open elements
+	names []string
+	// scope is the number of elements opened before the current scope
+	scope int
+}
+
+func (s *htmlStack) push(a *astHTML, name string) {
+	if s == nil {
+		return
+	}
+	s.open = append(s.open, a)
+	s.names = append(s.names, name)
+}
+
+// close closes the element name and the elements opened after it.
+func (s *htmlStack) close(a *astHTML, name string, opt *Option) (template.HTML, *ParseError) {
+	if s == nil {
+		return template.HTML("</" + name + ">"), nil
+	}
+	i := len(s.names) - 1
+	for i >= s.scope && s.names[i] != name {
+		i--
+	}
+	if i < s.scope {
+		err := &ParseError{
+			lxs:      a.lxs,
+			internal: errors.Join(ErrUnexpectedHTML, fmt.Errorf("element: %s", name)),
+		}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		return "", nil
+	}
+	res, err := s.closeUntil(i+1, opt)
+	if err != nil {
+		return "", err
+	}
+	s.open, s.names = s.open[:i], s.names[:i]
+	return res + template.HTML("</"+name+">"), nil
+}
+
+// closeUntil closes the elements opened after the n first ones.
+// An error is returned for each of them, because they were not closed.
+func (s *htmlStack) closeUntil(n int, opt *Option) (template.HTML, *ParseError) {
+	var res template.HTML
+	for i := len(s.names) - 1; i >= n; i-- {
+		err := &ParseError{
+			lxs:      s.open[i].lxs,
+			internal: errors.Join(ErrUnclosedHTML, fmt.Errorf("element: %s", s.names[i])),
+		}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		res += template.HTML("</" + s.names[i] + ">")
+	}
+	s.open, s.names = s.open[:n], s.names[:n]
+	return res, nil
+}
+
+// enter starts a new scope and returns the previous one, given to leave.
+func (s *htmlStack) enter() int {
+	if s == nil {
+		return 0
+	}
+	prev := s.scope
+	s.scope = len(s.names)
+	return prev
+}
+
+// leave closes the elements opened in the current scope and restores the previous one.
+func (s *htmlStack) leave(prev int, opt *Option) (template.HTML, *ParseError) {
+	if s == nil {
+		return "", nil
+	}
+	res, err := s.closeUntil(s.scope, opt)
+	s.scope = prev
+	return res, err
+}
+
+// astHTMLBlock is a raw HTML block, ended by an empty line.
+// Markdown is not parsed inside of it.
+type astHTMLBlock struct {
+	content []block
+}
+
+func (a *astHTMLBlock) Eval(opt *Option) (template.HTML, *ParseError) {
+	var content template.HTML
+	for _, c := range a.content {
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		content += ct
+	}
+	return template.HTML(strings.TrimSpace(string(content))), nil
+}
+
+func (a *astHTMLBlock) Kind() NodeKind {
+	return KindHTMLBlock
+}
+
+func (a *astHTMLBlock) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astHTMLBlock) Attr(string) string {
+	return ""
+}
+
+func htmlBlock(lxs *lexers) *astHTMLBlock {
+	tree := new(astHTMLBlock)
+	lxs.Before() // because we do not use it before the next
+	for lxs.Next() {
+		switch lxs.Current().Type {
+		case lexerHTML:
+			tree.content = append(tree.content, &astHTML{raw: lxs.Current().Value, lxs: *lxs})
+		case lexerBreak:
+			if len(lxs.Current().Value) > 1 {
+				return tree
+			}
+			tree.content = append(tree.content, astLiteral("\n"))
+		default:
+			tree.content = append(tree.content, astLiteral(lxs.Current().Value))
+		}
+	}
+	return tree
+}
+
+// isHTMLBlock returns true if the current lexer starts an HTML block.
+func isHTMLBlock(lxs *lexers) bool {
+	tag, _ := parseHTMLTag([]rune(lxs.Current().Value), 0)
+	return htmlBlocks[tag.name]
+}
+
+// htmlTagLength returns the length of the allowed HTML tag starting at i.
+// It returns 0 if there is no tag or if it is not allowed.
+func htmlTagLength(runes []rune, i int, allowed map[string][]string) int {
+	if allowed == nil {
+		return 0
+	}
+	tag, n := parseHTMLTag(runes, i)
+	if _, ok := allowed[tag.name]; !ok {
+		return 0
+	}
+	return n
+}
+
+// parseHTMLTag parses the tag starting at i on one line.
+// It returns 0 if it is not a valid tag.
+func parseHTMLTag(runes []rune, i int) (htmlTag, int) {
+	var tag htmlTag
+	j := i + 1
+	if j < len(runes) && runes[j] == '/' {
+		tag.closing = true
+		j++
+	}
+	name := readHTMLName(runes, j)
+	if len(name) == 0 || !unicode.IsLetter([]rune(name)[0]) {
+		return htmlTag{}, 0
+	}
+	tag.name = strings.ToLower(name)
+	j += len([]rune(name))
+	for j < len(runes) {
+		for j < len(runes) && (runes[j] == ' ' || runes[j] == '\t') {
+			j++
+		}
+		if j >= len(runes) {
+			break
+		}
+		switch {
+		case runes[j] == '>':
+			return tag, j - i + 1
+		case runes[j] == '/' && j+1 < len(runes) && runes[j+1] == '>' && !tag.closing:
+			return tag, j - i + 2
+		case tag.closing:
+			return htmlTag{}, 0
+		}
+		k := readHTMLName(runes, j)
+		if len(k) == 0 {
+			return htmlTag{}, 0
+		}
+		j += len([]rune(k))
+		var v string
+		if j < len(runes) && runes[j] == '=' {
+			j++
+			if j >= len(runes) {
+				break
+			}
+			end := j
+			if q := runes[j]; q == '"' || q == '\'' {
+				for end = j + 1; end < len(runes) && runes[end] != q && runes[end] != '\n'; end++ {
+				}
+				if end >= len(runes) || runes[end] != q {
+					return htmlTag{}, 0
+				}
+				v = string(runes[j+1 : end])
+				end++
+			} else {
+				for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`"'<>=`+"`", runes[end]) {
+					end++
+				}
+				v = string(runes[j:end])
+			}
+			j = end
+		}
+		tag.attrs = append(tag.attrs, [2]string{strings.ToLower(k), v})
+	}
+	return htmlTag{}, 0
+}
+
+func readHTMLName(runes []rune, i int) string {
+	j := i
+	for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '-' || runes[j] == '_' || runes[j] == ':') {
+		j++
+	}
+	return string(runes[i:j])
+}
+
+// safeURL returns true if the URL is relative or uses a safe scheme.
+func safeURL(u string) bool {
+	i := strings.IndexAny(u, ":/?#")
+	if i < 0 || u[i] != ':' {
+		return true
+	}
+	// browsers ignore control characters and spaces in schemes
+	scheme := strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u[:i]))
+	return scheme == "http" || scheme == "https" || scheme == "mailto"
+}
@@ -0,0 +1,60 @@
+package markdown
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	opt := func() *Option {
+		return &Option{HTML: DefaultHTML, ImageSource: func(s string) string { return "/static/" + s }}
+	}
+	t.Run("inline", func(t *testing.T) {
+		t.Run("simple", testWithOptions(opt(), `Appuyez sur <kbd>Ctrl</kbd> + <kbd>C</kbd>`,
+			`<p>Appuyez sur <kbd>Ctrl</kbd> + <kbd>C</kbd></p>`))
+		t.Run("attributes", testWithOptions(opt(), `Le <abbr title="HyperText &amp; co" onclick="alert(1)">HTML</abbr>`,
+			`<p>Le <abbr title="HyperText &amp; co">HTML</abbr></p>`))
+		t.Run("modifier", testWithOptions(opt(), `**<kbd>Ctrl</kbd>**`, `<p><b><kbd>Ctrl</kbd></b></p>`))
+		t.Run("not allowed", testWithOptions(opt(), `<script>alert(1)</script>`,
+			`<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>`))
+		t.Run("disabled", test(`<kbd>Ctrl</kbd>`, `<p>&lt;kbd&gt;Ctrl&lt;/kbd&gt;</p>`))
+		t.Run("code", testWithOptions(opt(), "`<kbd>`", `<p><code>&lt;kbd&gt;</code></p>`))
+		t.Run("void", testWithOptions(opt(), `a<br/>b<br>c</br>`, `<p>a<br>b<br>c</p>`))
+		t.Run("invalid", testWithOptions(opt(), `<kbd title="a>b`, `<p>&lt;kbd title=&#34;a&gt;b</p>`))
+	})
+	t.Run("block", func(t *testing.T) {
+		t.Run("details", testWithOptions(opt(), "<details open>\n<summary>Plus</summary>\n\nDu **texte**\n\n</details>",
+			"<details open=\"\">\n<summary>Plus</summary><p>Du <b>texte</b></p></details>"))
+		t.Run("audio", testWithOptions(opt(), `<audio controls src="son.mp3"></audio>`,
+			`<audio controls="" src="/static/son.mp3"></audio>`))
+		t.Run("raw", testWithOptions(opt(), "<video controls>\n*pas supporté*\n</video>",
+			"<video controls=\"\">\n*pas supporté*\n</video>"))
+		t.Run("paragraph", testWithOptions(opt(), "Un son\n<audio src=\"son.mp3\"></audio>",
+			`<p>Un son</p><audio src="/static/son.mp3"></audio>`))
+	})
+	t.Run("balance", func(t *testing.T) {
+		lenient := func() *Option {
+			o := opt()
+			o.Lenient = true
+			return o
+		}
+		t.Run("unclosed", testWithOptions(lenient(), `a <kbd>b`, `<p>a <kbd>b</kbd></p>`))
+		t.Run("modifier", testWithOptions(lenient(), `*<u>a* b</u>`, `<p><em><u>a</u></em> b</p>`))
+		t.Run("nested", testWithOptions(lenient(), `<q><kbd>a</q>`, `<p><q><kbd>a</kbd></q></p>`))
+		t.Run("unexpected", testWithOptions(lenient(), "</details>\n\na</kbd>", `<p>a</p>`))
+		t.Run("block", testWithOptions(lenient(), "<details>\n<summary>Plus</summary>\n\nDu texte",
+			"<details>\n<summary>Plus</summary><p>Du texte</p></details>"))
+		if _, err := Parse(`a <kbd>b`, opt()); !errors.Is(err, ErrUnclosedHTML) {
+			t.Errorf("invalid error, got %v", err)
+		}
+		if _, err := Parse(`a</kbd>`, opt()); !errors.Is(err, ErrUnexpectedHTML) {
+			t.Errorf("invalid error, got %v", err)
+		}
+	})
+	t.Run("sanitize", func(t *testing.T) {
+		t.Run("javascript", testWithOptions(opt(), `<audio src="java&#x09;script:alert(1)"></audio>`, `<audio></audio>`))
+		t.Run("scheme", testWithOptions(opt(), `<q cite="JavaScript:alert(1)">a</q> <q cite="https://example.org">b</q>`,
+			`<p><q>a</q> <q cite="https://example.org">b</q></p>`))
+		t.Run("duplicate", testWithOptions(opt(), `<abbr title="a" title="b">c</abbr>`, `<p><abbr title="a">c</abbr></p>`))
+	})
+}
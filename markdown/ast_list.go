@@ -3,12 +3,13 @@ package markdown
 import (
 	"html/template"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"anhgelus.world/small-web/dom"
 )
 
-var regexOrdered = regexp.MustCompile(`\d+\.`)
+var regexOrdered = regexp.MustCompile(`^\d+\.$`)
 
 type listType string
 
@@ -18,13 +19,17 @@ const (
 )
 
 type astList struct {
-	tag     listType
-	content []*astParagraph
+	tag   listType
+	start int
+	items []*astListItem
 }
 
 func (a *astList) Eval(opt *Option) (template.HTML, *ParseError) {
-	list := dom.NewContentElement(string(a.tag), make([]dom.Element, 0))
-	for _, c := range a.content {
+	list := dom.NewContentElement(string(a.tag), make([]dom.Element, 0, len(a.items)))
+	if a.tag == listOrdered && a.start != 1 {
+		list.SetAttribute("start", strconv.Itoa(a.start))
+	}
+	for _, c := range a.items {
 		ct, err := c.Eval(opt)
 		if err != nil {
 			return "", err
@@ -37,39 +42,255 @@ func (a *astList) Eval(opt *Option) (template.HTML, *ParseError) {
 	return list.Render(), nil
 }
 
+func (a *astList) Kind() NodeKind {
+	return KindList
+}
+
+func (a *astList) Children() []Node {
+	return nodes(a.items)
+}
+
+func (a *astList) Attr(key string) string {
+	switch key {
+	case "type":
+		return string(a.tag)
+	case "start":
+		if a.tag != listOrdered {
+			return ""
+		}
+		return strconv.Itoa(a.start)
+	default:
+		return ""
+	}
+}
+
+type astListItem struct {
+	content []block
+}
+
+func (a *astListItem) Eval(opt *Option) (template.HTML, *ParseError) {
+	var content template.HTML
+	for _, c := range a.content {
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		content += ct
+	}
+	return content, nil
+}
+
+func (a *astListItem) Kind() NodeKind {
+	return KindListItem
+}
+
+func (a *astListItem) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astListItem) Attr(string) string {
+	return ""
+}
+
 func list(lxs *lexers) (block, *ParseError) {
 	tree := new(astList)
 	tree.tag = detectListType(lxs.Current().Value)
 	if len(tree.tag) == 0 {
 		return paragraph(lxs, false)
 	}
-	n := 0
-	for lxs.Next() && n < 2 {
-		switch lxs.Current().Type {
-		case lexerBreak:
-			n += len(lxs.Current().Value)
-		case lexerList:
-			n = 0
-			tp := detectListType(lxs.Current().Value)
-			if tp != tree.tag {
-				lxs.Before() // because we dit not use it
-				return tree, nil
+	tree.start = listStart(lxs.Current().Value)
+	i := lxs.current
+	for {
+		item, end, err := listItem(lxs, i)
+		if err != nil {
+			return nil, err
+		}
+		tree.items = append(tree.items, item)
+		lxs.current = end
+		// end is the break ending the item
+		next := end + 1
+		for next < len(lxs.lexers) && isBlankLine(lxs.lexers, next) {
+			next = lineEnd(lxs.lexers, next) + 1
+		}
+		if next >= len(lxs.lexers) || detectListType(listMarker(lxs.lexers, next)) != tree.tag {
+			return tree, nil
+		}
+		i = next
+	}
+}
+
+// listItem parses the item starting at the marker i.
+// It returns the index of the last lexer used by this item.
+func listItem(lxs *lexers, i int) (*astListItem, int, *ParseError) {
+	l := lxs.lexers
+	var tokens []lexer
+	j := lineEnd(l, i+1)
+	first := l[i+1 : j]
+	if len(first) > 0 && first[0].Type == lexerLiteral {
+		v := strings.TrimLeft(first[0].Value, " \t")
+		if len(v) > 0 {
+			lx := first[0]
+			lx.Column += len([]rune(lx.Value)) - len([]rune(v))
+			lx.Value = v
+			tokens = append(tokens, lx)
+		}
+		first = first[1:]
+	}
+	tokens = append(tokens, first...)
+	indent := -1
+	end := j
+	for j < len(l) {
+		// j is the break ending the previous line
+		blank := len(l[j].Value) > 1
+		k := j + 1
+		for k < len(l) && isBlankLine(l, k) {
+			blank = true
+			k = lineEnd(l, k) + 1
+		}
+		if k >= len(l) {
+			break
+		}
+		lineIndent := indentation(l[k])
+		line := l[k:lineEnd(l, k)]
+		switch {
+		case lineIndent > 0:
+			if indent < 0 {
+				indent = lineIndent
 			}
+			line = dedent(line, indent)
+		case !blank && isLazyContinuation(l, k) && !isThematicBreak(l, k):
 		default:
-			n = 0
-			c, err := paragraph(lxs, true)
-			if err != nil {
-				return nil, err
+			return parseListItem(tokens, end, lxs.warnings)
+		}
+		brk := "\n"
+		if blank {
+			brk = "\n\n"
+		}
+		tokens = append(tokens, lexer{Type: lexerBreak, Value: brk, Line: l[j].Line, Column: l[j].Column})
+		tokens = append(tokens, line...)
+		j = lineEnd(l, k)
+		end = j
+	}
+	return parseListItem(tokens, min(end, len(l)-1), lxs.warnings)
+}
+
+func parseListItem(tokens []lexer, end int, w *warnings) (*astListItem, int, *ParseError) {
+	tr, err := ast(&lexers{current: -1, lexers: tokens, warnings: w})
+	if err != nil {
+		return nil, 0, err
+	}
+	item := &astListItem{content: tr.blocks}
+	paragraphs := 0
+	for _, b := range item.content {
+		if _, ok := b.(*astParagraph); ok {
+			paragraphs++
+		}
+	}
+	// a tight item does not wrap its text in a paragraph
+	if paragraphs <= 1 {
+		for _, b := range item.content {
+			if p, ok := b.(*astParagraph); ok {
+				p.oneLine = true
 			}
-			lxs.Before() // because we must parse the last char
-			tree.content = append(tree.content, c)
 		}
 	}
-	lxs.Before() // because we did not use it
-	return tree, nil
+	return item, end, nil
+}
+
+// lineEnd returns the index of the break ending the line containing i.
+func lineEnd(l []lexer, i int) int {
+	for i < len(l) && l[i].Type != lexerBreak {
+		i++
+	}
+	return i
+}
+
+func isBlankLine(l []lexer, i int) bool {
+	end := lineEnd(l, i)
+	for ; i < end; i++ {
+		if l[i].Type != lexerLiteral || len(strings.TrimSpace(l[i].Value)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isLazyContinuation(l []lexer, i int) bool {
+	switch l[i].Type {
+	case lexerLiteral, lexerModifier, lexerReplace, lexerFootnote, lexerWikiLink:
+		return true
+	case lexerExternal:
+		return l[i].Value != "!["
+	case lexerList:
+		return len(detectListType(l[i].Value)) == 0
+	default:
+		return false
+	}
+}
+
+// listMarker returns the value of the list marker starting the line i, if it is not indented.
+func listMarker(l []lexer, i int) string {
+	if l[i].Type != lexerList {
+		return ""
+	}
+	return l[i].Value
+}
+
+// indentation returns the number of spaces before the content of the line starting with lx.
+func indentation(lx lexer) int {
+	if lx.Type != lexerLiteral {
+		return 0
+	}
+	n := 0
+	for _, c := range lx.Value {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// dedent removes at most n spaces before the content of the line.
+func dedent(line []lexer, n int) []lexer {
+	if len(line) == 0 || line[0].Type != lexerLiteral {
+		return line
+	}
+	v := line[0].Value
+	i := 0
+	for removed := 0; i < len(v) && removed < n && (v[i] == ' ' || v[i] == '\t'); i++ {
+		if v[i] == '\t' {
+			removed += 4
+		} else {
+			removed++
+		}
+	}
+	res := make([]lexer, 0, len(line))
+	if len(v[i:]) > 0 {
+		lx := line[0]
+		lx.Value = v[i:]
+		lx.Column += i
+		res = append(res, lx)
+	}
+	return append(res, line[1:]...)
+}
+
+func listStart(val string) int {
+	n, err := strconv.Atoi(strings.TrimSuffix(val, "."))
+	if err != nil {
+		return 1
+	}
+	return n
 }
 
 func detectListType(val string) listType {
+	if len(val) == 0 {
+		return ""
+	}
 	first := []rune(val)[0]
 	if first == '-' || first == '*' {
 		if len(val) > 1 {
@@ -31,3 +31,45 @@ func TestList(t *testing.T) {
 		t.Run("combo", test(rw, strings.ReplaceAll(expected, "\n", "")))
 	})
 }
+
+var rwNested = `
+- item A
+  - item A.1
+  - item A.2
+    1. item A.2.1
+- item B
+    * item B.1
+`
+
+var expectedNested = `
+<ul>
+<li>item A<ul><li>item A.1</li><li>item A.2<ol><li>item A.2.1</li></ol></li></ul></li>
+<li>item B<ul><li>item B.1</li></ul></li>
+</ul>
+`
+
+var rwBlocks = `
+3. premier paragraphe
+
+   second paragraphe
+4. une citation
+   > Bonsoir
+`
+
+var expectedBlocks = `
+<ol start="3">
+<li><p>premier paragraphe</p><p>second paragraphe</p></li>
+<li>une citation<div class="quote"><blockquote>Bonsoir</blockquote></div></li>
+</ol>
+`
+
+func TestNestedList(t *testing.T) {
+	t.Run("lists", func(t *testing.T) {
+		t.Run("nested", test(rwNested, strings.ReplaceAll(expectedNested, "\n", "")))
+		t.Run("blocks", test(rwBlocks, strings.ReplaceAll(expectedBlocks, "\n", "")))
+		t.Run("code", test("- du code\n  ```\n  raw\n  ```", `<ul><li>du code<pre><code>raw
+</code></pre></li></ul>`))
+		t.Run("start", test("0. zéro\n1. un", `<ol start="0"><li>zéro</li><li>un</li></ol>`))
+		t.Run("lazy", test("- item\nsuite\n\nparagraphe", `<ul><li>item suite</li></ul><p>paragraphe</p>`))
+	})
+}
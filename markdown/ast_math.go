@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"html/template"
+	"strings"
+)
+
+// astMath is a formula converted into MathML.
+type astMath struct {
+	source  string
+	display bool
+	content template.HTML
+}
+
+func (a *astMath) Eval(_ *Option) (template.HTML, *ParseError) {
+	return a.content, nil
+}
+
+func (a *astMath) Kind() NodeKind {
+	return KindMath
+}
+
+func (a *astMath) Children() []Node {
+	return nil
+}
+
+func (a *astMath) Attr(key string) string {
+	switch key {
+	case "tex":
+		return a.tex()
+	case "display":
+		if a.display {
+			return "true"
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+// tex returns the source without its delimiters.
+func (a *astMath) tex() string {
+	n := 1
+	if a.display {
+		n = 2
+	}
+	return strings.TrimSpace(a.source[n : len(a.source)-n])
+}
+
+func math(lxs *lexers) (*astMath, *ParseError) {
+	tree := &astMath{source: lxs.Current().Value}
+	tree.display = strings.HasPrefix(tree.source, "$$")
+	var err error
+	tree.content, err = mathML(tree.tex(), tree.display)
+	if err != nil {
+		return nil, &ParseError{lxs: *lxs, internal: err}
+	}
+	return tree, nil
+}
+
+// isMathBlock returns true if the current lexer is a display formula alone on its line.
+func isMathBlock(lxs *lexers) bool {
+	next := lxs.current + 1
+	return strings.HasPrefix(lxs.Current().Value, "$$") &&
+		(next >= len(lxs.lexers) || lxs.lexers[next].Type == lexerBreak)
+}
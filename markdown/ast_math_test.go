@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"html/template"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	fn := func(tex, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			res, err := mathML(tex, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = `<math><semantics><mrow>` + expected + `</mrow><annotation encoding="application/x-tex">` +
+				template.HTMLEscapeString(tex) + `</annotation></semantics></math>`
+			if string(res) != expected {
+				t.Errorf("invalid value, got %s", res)
+			}
+		}
+	}
+	t.Run("mathml", func(t *testing.T) {
+		t.Run("simple", fn(`x+1`, `<mi>x</mi><mo>+</mo><mn>1</mn>`))
+		t.Run("scripts", fn(`x_i^2`, `<msubsup><mi>x</mi><mi>i</mi><mn>2</mn></msubsup>`))
+		t.Run("one digit", fn(`x^23`, `<msup><mi>x</mi><mn>2</mn></msup><mn>3</mn>`))
+		t.Run("frac", fn(`\frac{a}{b-1}`, `<mfrac><mrow><mi>a</mi></mrow><mrow><mi>b</mi><mo>−</mo><mn>1</mn></mrow></mfrac>`))
+		t.Run("sqrt", fn(`\sqrt[3]{x}`, `<mroot><mrow><mi>x</mi></mrow><mrow><mn>3</mn></mrow></mroot>`))
+		t.Run("greek", fn(`\alpha\Omega`, `<mi>α</mi><mi mathvariant="normal">Ω</mi>`))
+		t.Run("sum", fn(`\sum_{i=0}^n i`,
+			`<munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>0</mn></mrow><mi>n</mi></munderover><mi>i</mi>`))
+		t.Run("function", fn(`\sin x`, `<mi>sin</mi><mi>x</mi>`))
+		t.Run("text", fn(`\text{si } x<0`, `<mtext>si </mtext><mi>x</mi><mo>&lt;</mo><mn>0</mn>`))
+		t.Run("mathbb", fn(`\mathbb{R}`, `<mi>ℝ</mi>`))
+		t.Run("fence", fn(`\left(x\right]`, `<mrow><mo>(</mo><mi>x</mi><mo>]</mo></mrow>`))
+		t.Run("interval", fn(`[0, 1]`, `<mo>[</mo><mn>0</mn><mo>,</mo><mn>1</mn><mo>]</mo>`))
+		t.Run("matrix", fn(`\begin{pmatrix}a & b\\c & d\end{pmatrix}`,
+			`<mrow><mo>(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr>`+
+				`<mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable><mo>)</mo></mrow>`))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, tex := range []string{`\unknown`, `\frac{a}`, `{x`, `x}`, `\left(x`, `\begin{pmatrix}a\end{bmatrix}`} {
+			if _, err := mathML(tex, false); err == nil {
+				t.Errorf("expected error for %s", tex)
+			}
+		}
+	})
+}
+
+func TestMathMarkdown(t *testing.T) {
+	t.Run("markdown", func(t *testing.T) {
+		t.Run("inline", test(`soit $x$ un réel`,
+			`<p>soit <math><semantics><mrow><mi>x</mi></mrow><annotation encoding="application/x-tex">x</annotation></semantics></math> un réel</p>`))
+		t.Run("display", test("$$\nx^2\n$$",
+			`<math display="block"><semantics><mrow><msup><mi>x</mi><mn>2</mn></msup></mrow><annotation encoding="application/x-tex">x^2</annotation></semantics></math>`))
+		t.Run("modifier", test(`**$x$**`,
+			`<p><b><math><semantics><mrow><mi>x</mi></mrow><annotation encoding="application/x-tex">x</annotation></semantics></math></b></p>`))
+		t.Run("prices", test(`entre $5 et $10`, `<p>entre $5 et $10</p>`))
+		t.Run("spaces", test(`$ x $`, `<p>$ x $</p>`))
+		t.Run("escaped", test(`\$x$`, `<p>$x$</p>`))
+		t.Run("code", test("`$x$`", `<p><code>$x$</code></p>`))
+	})
+	t.Run("lenient", func(t *testing.T) {
+		doc, err := ParseDocument(`un $\foo$ inconnu`, &Option{Lenient: true})
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		res, err := doc.HTML()
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		if string(res) != `<p>un $\foo$ inconnu</p>` || len(doc.Warnings()) != 1 {
+			t.Errorf("invalid value, got %s %v", res, doc.Warnings())
+		}
+	})
+}
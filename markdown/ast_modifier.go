@@ -19,6 +19,10 @@ type modifierTag string
 const (
 	boldTag modifierTag = "b"
 	emTag   modifierTag = "em"
+	delTag  modifierTag = "del"
+	markTag modifierTag = "mark"
+	supTag  modifierTag = "sup"
+	subTag  modifierTag = "sub"
 )
 
 type astModifier struct {
@@ -29,13 +33,9 @@ type astModifier struct {
 }
 
 func (a *astModifier) Eval(opt *Option) (template.HTML, *ParseError) {
-	var content template.HTML
-	for _, c := range a.content {
-		ct, err := c.Eval(opt)
-		if err != nil {
-			return "", &ParseError{lxs: lexers{}, internal: err}
-		}
-		content += ct
+	content, err := evalInline(a.content, opt)
+	if err != nil {
+		return "", err
 	}
 	if a.super {
 		return content, nil
@@ -43,6 +43,31 @@ func (a *astModifier) Eval(opt *Option) (template.HTML, *ParseError) {
 	return dom.NewLiteralContentElement(string(a.tag), content).Render(), nil
 }
 
+func (a *astModifier) Kind() NodeKind {
+	switch a.tag {
+	case emTag:
+		return KindEmphasis
+	case delTag:
+		return KindStrikethrough
+	case markTag:
+		return KindMark
+	case supTag:
+		return KindSuperscript
+	case subTag:
+		return KindSubscript
+	default:
+		return KindStrong
+	}
+}
+
+func (a *astModifier) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astModifier) Attr(string) string {
+	return ""
+}
+
 func (a *astModifier) String() string {
 	content := "["
 	for _, c := range a.content {
@@ -68,7 +93,7 @@ func modifier(lxs *lexers) (*astModifier, error) {
 	var s string
 	for lxs.Next() {
 		switch lxs.Current().Type {
-		case lexerLiteral, lexerHeading, lexerList:
+		case lexerLiteral, lexerHeading, lexerList, lexerTable:
 			s += lxs.Current().Value
 		case lexerModifier:
 			if mod.super && []rune(mod.symbols)[0] == []rune(lxs.Current().Value)[0] &&
@@ -115,6 +140,48 @@ func modifier(lxs *lexers) (*astModifier, error) {
 				return nil, ErrInvalidUsage
 			}
 			return mod, nil
+		case lexerReplace:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			mod.content = append(mod.content, astReplacer{symbol: lxs.Current().Value, replace: lxs.Current().Replace})
+		case lexerHardBreak:
+			// the line ends with the modifier, the break is handled after
+		case lexerWikiLink:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			mod.content = append(mod.content, wikiLink(lxs))
+		case lexerDirective:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			mod.content = append(mod.content, shortcode(lxs, false))
+		case lexerMath:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			m, err := math(lxs)
+			if err != nil {
+				return nil, err.internal
+			}
+			mod.content = append(mod.content, m)
+		case lexerHTML:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			mod.content = append(mod.content, &astHTML{raw: lxs.Current().Value, lxs: *lxs})
+		case lexerFootnote:
+			if len(s) != 0 {
+				mod.content = append(mod.content, astLiteral(s))
+				s = ""
+			}
+			mod.content = append(mod.content, footnoteRef(lxs))
 		case lexerExternal:
 			if lxs.Current().Value == "!" {
 				s += lxs.Current().Value
@@ -129,15 +196,27 @@ func modifier(lxs *lexers) (*astModifier, error) {
 			return nil, ErrInvalidTypeInModifier
 		}
 	}
-	if len(s) != 0 {
-		return nil, ErrInvalidUsage
-	}
-	return mod, nil
+	// the modifier is never closed
+	return nil, ErrInvalidUsage
 }
 
 func modifierDetect(val string) (*astModifier, error) {
 	mod := new(astModifier)
 	mod.symbols = val
+	switch val {
+	case "~~":
+		mod.tag = delTag
+		return mod, nil
+	case "==":
+		mod.tag = markTag
+		return mod, nil
+	case "^":
+		mod.tag = supTag
+		return mod, nil
+	case "~":
+		mod.tag = subTag
+		return mod, nil
+	}
 	switch len(val) {
 	case 1:
 		mod.tag = emTag
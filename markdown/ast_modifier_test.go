@@ -5,5 +5,29 @@ import "testing"
 func TestModifier(t *testing.T) {
 	t.Run("modifiers", func(t *testing.T) {
 		t.Run("combo", test(`**bo*n*soir**, ça ***va* bien** ?`, `<p><b>bo<em>n</em>soir</b>, ça <b><em>va</em> bien</b> ?</p>`))
+		t.Run("strike", test(`du ~~texte barré~~`, `<p>du <del>texte barré</del></p>`))
+		t.Run("mark", test(`du ==texte *surligné*==`, `<p>du <mark>texte <em>surligné</em></mark></p>`))
+		t.Run("sup", test(`le 1^er^ et x^2^`, `<p>le 1<sup>er</sup> et x<sup>2</sup></p>`))
+		t.Run("sub", test(`H~2~O`, `<p>H<sub>2</sub>O</p>`))
+		t.Run("not closed", test(`a == b et 2^ 3 ~ 4`, `<p>a == b et 2^ 3 ~ 4</p>`))
+		t.Run("spaces", test(`un ^pas sup^ ni ~pas sub~`, `<p>un ^pas sup^ ni ~pas sub~</p>`))
+	})
+	t.Run("replaces", func(t *testing.T) {
+		fn := func(s, expected string) func(*testing.T) {
+			return func(t *testing.T) {
+				t.Parallel()
+				res, err := Parse(s, &Option{Replaces: []Replacer{{Symbol: "~", Replace: "&thinsp;"}}})
+				if err != nil {
+					t.Fatal(err.Pretty())
+				}
+				if string(res) != expected {
+					t.Errorf("invalid value, got %s", res)
+				}
+			}
+		}
+		t.Run("thin space", fn(`Bonjour~! Ça va~?`, `<p>Bonjour&thinsp;! Ça va&thinsp;?</p>`))
+		t.Run("strike", fn(`~~barré~~ et ça~!`, `<p><del>barré</del> et ça&thinsp;!</p>`))
+		t.Run("in modifier", fn(`**Salut~!**`, `<p><b>Salut&thinsp;!</b></p>`))
+		t.Run("sub", fn(`H~2~O~!`, `<p>H<sub>2</sub>O&thinsp;!</p>`))
 	})
 }
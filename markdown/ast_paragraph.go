@@ -18,13 +18,10 @@ type astParagraph struct {
 }
 
 func (a *astParagraph) Eval(opt *Option) (template.HTML, *ParseError) {
-	var content template.HTML
-	for _, c := range a.content {
-		ct, err := c.Eval(opt)
-		if err != nil {
-			return "", err
-		}
-		content += ct
+	opt.typography.reset()
+	content, err := evalInline(a.content, opt)
+	if err != nil {
+		return "", err
 	}
 	if a.oneLine {
 		return content, nil
@@ -34,15 +31,45 @@ func (a *astParagraph) Eval(opt *Option) (template.HTML, *ParseError) {
 	).Render(), nil
 }
 
-type astBreak struct{}
+func (a *astParagraph) Kind() NodeKind {
+	return KindParagraph
+}
+
+func (a *astParagraph) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astParagraph) Attr(string) string {
+	return ""
+}
+
+type astBreak struct {
+	// hard is true if the line ends with a backslash or with two spaces
+	hard bool
+}
 
 func (a astBreak) Eval(opt *Option) (template.HTML, *ParseError) {
-	if opt.Poem {
+	if opt.Poem || a.hard {
 		return dom.NewVoidElement("br").Render(), nil
 	}
 	return " ", nil
 }
 
+func (a astBreak) Kind() NodeKind {
+	return KindBreak
+}
+
+func (a astBreak) Children() []Node {
+	return nil
+}
+
+func (a astBreak) Attr(key string) string {
+	if key == "hard" && a.hard {
+		return "true"
+	}
+	return ""
+}
+
 func paragraph(lxs *lexers, oneLine bool) (*astParagraph, *ParseError) {
 	tree := new(astParagraph)
 	tree.oneLine = oneLine
@@ -51,14 +78,21 @@ func paragraph(lxs *lexers, oneLine bool) (*astParagraph, *ParseError) {
 		maxBreak = 1
 	}
 	n := 0
+	hard := false
 	lxs.Before() // because we do not use it before the next
 	for lxs.Next() && n < maxBreak {
+		if n > 0 && (isThematicBreak(lxs.lexers, lxs.current) || isDefinitionTerm(lxs.lexers, lxs.current)) {
+			lxs.Before() // because we did not use it
+			return tree, nil
+		}
 		var err *ParseError
 		var b block
 		switch lxs.Current().Type {
 		case lexerBreak:
 			n += len(lxs.Current().Value)
-		case lexerQuote, lexerList:
+		case lexerHardBreak:
+			hard = true
+		case lexerQuote, lexerList, lexerTable:
 			if n > 0 {
 				lxs.Before() // because we did not use it
 				return tree, nil
@@ -67,12 +101,50 @@ func paragraph(lxs *lexers, oneLine bool) (*astParagraph, *ParseError) {
 		case lexerLiteral, lexerHeading:
 			b = astLiteral(lxs.Current().Value)
 		case lexerReplace:
-			b = astReplacer(lxs.Current().Value)
+			b = astReplacer{symbol: lxs.Current().Value, replace: lxs.Current().Replace}
+		case lexerWikiLink:
+			b = wikiLink(lxs)
+		case lexerDirective:
+			switch {
+			case n > 0 && (strings.HasPrefix(lxs.Current().Value, ":") || isShortcodeBlock(lxs)):
+				lxs.Before() // because we did not use it
+				return tree, nil
+			case strings.HasPrefix(lxs.Current().Value, ":"):
+				// invalid fence recovered
+				b = astLiteral(lxs.Current().Value)
+			default:
+				b = shortcode(lxs, false)
+			}
+		case lexerMath:
+			if n > 0 && isMathBlock(lxs) {
+				lxs.Before() // because we did not use it
+				return tree, nil
+			}
+			b, err = math(lxs)
+			if err != nil && lxs.warnings.recover(err) {
+				b, err = astLiteral(lxs.Current().Value), nil
+			}
+		case lexerHTML:
+			if n > 0 && isHTMLBlock(lxs) {
+				lxs.Before() // because we did not use it
+				return tree, nil
+			}
+			b = &astHTML{raw: lxs.Current().Value, lxs: *lxs}
+		case lexerFootnote:
+			if n > 0 && isFootnoteDefinition(lxs) {
+				lxs.Before() // because we did not use it
+				return tree, nil
+			}
+			b = footnoteRef(lxs)
 		case lexerModifier:
+			start := lxs.current
 			var e error
 			b, e = modifier(lxs)
 			if e != nil {
 				err = &ParseError{lxs: *lxs, internal: e}
+				if lxs.warnings.recover(err) {
+					b, err = reset(lxs, start), nil
+				}
 			}
 		case lexerExternal:
 			if n > 0 && lxs.Current().Value == "![" {
@@ -85,10 +157,20 @@ func paragraph(lxs *lexers, oneLine bool) (*astParagraph, *ParseError) {
 				b, err = external(lxs)
 			}
 		case lexerCode:
-			if len(lxs.Current().Value) > 1 {
+			if len(lxs.Current().Value) > 1 && n > 0 {
+				lxs.Before() // because we did not use it
+				return tree, nil
+			} else if len(lxs.Current().Value) > 1 {
 				err = &ParseError{lxs: *lxs, internal: ErrInvalidCodeBlockPosition}
+				if lxs.warnings.recover(err) {
+					b, err = astLiteral(lxs.Current().Value), nil
+				}
 			} else {
+				start := lxs.current
 				b, err = code(lxs)
+				if err != nil && lxs.warnings.recover(err) {
+					b, err = reset(lxs, start), nil
+				}
 			}
 		}
 
@@ -98,16 +180,19 @@ func paragraph(lxs *lexers, oneLine bool) (*astParagraph, *ParseError) {
 
 		if b != nil {
 			if n > 0 && len(tree.content) != 0 {
-				tree.content = append(tree.content, astBreak{})
+				tree.content = append(tree.content, astBreak{hard: hard})
 			}
+			hard = false
 			tree.content = append(tree.content, b)
 		}
 
-		if lxs.Current().Type != lexerBreak {
+		if !lxs.Finished() && lxs.Current().Type != lexerBreak {
 			n = 0
 		}
 	}
-	lxs.Before() // because we never handle the last item
+	if !lxs.Finished() {
+		lxs.Before() // because we never handle the last item
+	}
 	return tree, nil
 }
 
@@ -117,8 +202,79 @@ func (a astLiteral) Eval(_ *Option) (template.HTML, *ParseError) {
 	return template.HTML(template.HTMLEscapeString(string(a))), nil
 }
 
-type astReplacer string
+func (a astLiteral) Kind() NodeKind {
+	return KindText
+}
+
+func (a astLiteral) Children() []Node {
+	return nil
+}
+
+func (a astLiteral) Attr(string) string {
+	return ""
+}
+
+type astReplacer struct {
+	symbol  string
+	replace string
+}
+
+func (a astReplacer) Eval(_ *Option) (template.HTML, *ParseError) {
+	return template.HTML(a.replace), nil
+}
+
+func (a astReplacer) Kind() NodeKind {
+	return KindReplace
+}
+
+func (a astReplacer) Children() []Node {
+	return nil
+}
 
-func (a astReplacer) Eval(opt *Option) (template.HTML, *ParseError) {
-	return template.HTML(opt.Replaces[[]rune(a)[0]]), nil
+func (a astReplacer) Attr(key string) string {
+	switch key {
+	case "symbol":
+		return a.symbol
+	case "replace":
+		return a.replace
+	}
+	return ""
+}
+
+type astThematicBreak struct{}
+
+func (a astThematicBreak) Eval(_ *Option) (template.HTML, *ParseError) {
+	return dom.NewVoidElement("hr").Render(), nil
+}
+
+func (a astThematicBreak) Kind() NodeKind {
+	return KindThematicBreak
+}
+
+func (a astThematicBreak) Children() []Node {
+	return nil
+}
+
+func (a astThematicBreak) Attr(string) string {
+	return ""
+}
+
+func thematicBreak(lxs *lexers) astThematicBreak {
+	// the break ending the line is parsed after
+	lxs.current = lineEnd(lxs.lexers, lxs.current) - 1
+	return astThematicBreak{}
+}
+
+// isThematicBreak returns true if the line starting at i only contains at least three -, * or _.
+// They can be separated by spaces.
+func isThematicBreak(l []lexer, i int) bool {
+	var line strings.Builder
+	for _, lx := range l[i:lineEnd(l, i)] {
+		line.WriteString(lx.Value)
+	}
+	s := strings.ReplaceAll(strings.ReplaceAll(line.String(), " ", ""), "\t", "")
+	if len(s) < 3 || !strings.ContainsRune("-*_", rune(s[0])) {
+		return false
+	}
+	return strings.Count(s, s[:1]) == len(s)
 }
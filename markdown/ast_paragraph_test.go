@@ -1,15 +1,32 @@
 package markdown
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestParagraph(t *testing.T) {
 	t.Run("paragraph", func(t *testing.T) {
 		t.Run("simple", test("bonsoir", `<p>bonsoir</p>`))
 	})
 	t.Run("replacer", func(t *testing.T) {
-		opt := &Option{Replaces: map[rune]string{'~': "&thinsp;"}}
+		opt := &Option{Replaces: []Replacer{{Symbol: "~", Replace: "&thinsp;"}}}
 		t.Run("empty", testWithOptions(opt, "bonsoir", `<p>bonsoir</p>`))
 		t.Run("simple", testWithOptions(opt, "bonsoir~!", `<p>bonsoir&thinsp;!</p>`))
+		opt = &Option{Replaces: []Replacer{
+			{Symbol: "(c)", Replace: "&copy;"},
+			{Symbol: "->", Replace: "&rarr;"},
+			{Symbol: "--", Replace: "&ndash;"},
+			{Symbol: "~", Replace: "&thinsp;"},
+			{Pattern: regexp.MustCompile(`:(\w+):`), Replace: `<span class="$1"></span>`},
+		}}
+		t.Run("multi", testWithOptions(opt, "(c) 2024 -- a -> b", `<p>&copy; 2024 &ndash; a &rarr; b</p>`))
+		t.Run("pattern", testWithOptions(opt, "a :smile: b", `<p>a <span class="smile"></span> b</p>`))
+		t.Run("modifier", testWithOptions(opt, "~~a -- b~~ *c -> d*", `<p><del>a &ndash; b</del> <em>c &rarr; d</em></p>`))
+		t.Run("code", testWithOptions(opt, "`a -- b` (c)", `<p><code>a -- b</code> &copy;</p>`))
+		t.Run("escape", testWithOptions(opt, `\-- b`, `<p>-- b</p>`))
+		t.Run("list", testWithOptions(opt, "- a -> b", `<ul><li>a &rarr; b</li></ul>`))
+		t.Run("thematic break", testWithOptions(opt, "---", `<hr>`))
 	})
 	t.Run("poem", func(t *testing.T) {
 		opt := &Option{Poem: true}
@@ -22,3 +39,25 @@ world
 new line`, `<p>bonsoir<br>world</p><p>new line</p>`))
 	})
 }
+
+func TestThematicBreak(t *testing.T) {
+	t.Run("thematic break", func(t *testing.T) {
+		t.Run("dashes", test("avant\n\n---\n\naprès", `<p>avant</p><hr><p>après</p>`))
+		t.Run("stars", test("* * *", `<hr>`))
+		t.Run("underscores", test("___", `<hr>`))
+		t.Run("after paragraph", test("avant\n---\naprès", `<p>avant</p><hr><p>après</p>`))
+		t.Run("after list", test("- item\n---", `<ul><li>item</li></ul><hr>`))
+		t.Run("too short", test("--", `<p>--</p>`))
+	})
+}
+
+func TestHardBreak(t *testing.T) {
+	t.Run("hard break", func(t *testing.T) {
+		t.Run("backslash", test("bonsoir\\\nworld", `<p>bonsoir<br>world</p>`))
+		t.Run("spaces", test("bonsoir  \nworld", `<p>bonsoir<br>world</p>`))
+		t.Run("modifier", test("**bonsoir**   \nworld", `<p><b>bonsoir</b><br>world</p>`))
+		t.Run("one space", test("bonsoir \nworld", `<p>bonsoir  world</p>`))
+		t.Run("end", test("bonsoir\\\n\nworld", `<p>bonsoir</p><p>world</p>`))
+		t.Run("code", test("```\na  \nb\\\n```", "<pre><code>a  \nb\\\n</code></pre>"))
+	})
+}
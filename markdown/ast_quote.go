@@ -33,6 +33,40 @@ func (a *astQuote) Eval(opt *Option) (template.HTML, *ParseError) {
 	return quote.Render(), nil
 }
 
+func (a *astQuote) Kind() NodeKind {
+	return KindQuote
+}
+
+// Children returns the paragraphs of the quote followed by its citation, if any.
+func (a *astQuote) Children() []Node {
+	res := nodes(a.quote)
+	if len(a.source) > 0 {
+		res = append(res, &astCitation{content: a.source})
+	}
+	return res
+}
+
+func (a *astQuote) Attr(string) string {
+	return ""
+}
+
+// astCitation is the source of a quote.
+type astCitation struct {
+	content []*astParagraph
+}
+
+func (a *astCitation) Kind() NodeKind {
+	return KindCitation
+}
+
+func (a *astCitation) Children() []Node {
+	return nodes(a.content)
+}
+
+func (a *astCitation) Attr(string) string {
+	return ""
+}
+
 func quote(lxs *lexers) (block, *ParseError) {
 	tree := new(astQuote)
 	n := 0
@@ -0,0 +1,258 @@
+package markdown
+
+import (
+	"errors"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"anhgelus.world/small-web/dom"
+)
+
+var (
+	ErrInvalidTable          = errors.New("invalid table")
+	ErrInvalidTableDelimiter = errors.Join(ErrInvalidTable, errors.New("invalid delimiter row"))
+	ErrInvalidTableRow       = errors.Join(ErrInvalidTable, errors.New("invalid number of cells in row"))
+)
+
+var regexTableDelimiter = regexp.MustCompile(`^:?-+:?$`)
+
+type tableAlign string
+
+const (
+	alignNone   tableAlign = ""
+	alignLeft   tableAlign = "left"
+	alignCenter tableAlign = "center"
+	alignRight  tableAlign = "right"
+)
+
+type astTable struct {
+	header []*astParagraph
+	align  []tableAlign
+	rows   [][]*astParagraph
+}
+
+func (a *astTable) Eval(opt *Option) (template.HTML, *ParseError) {
+	head, err := a.evalRow(a.header, "th", opt)
+	if err != nil {
+		return "", err
+	}
+	body := dom.NewContentElement("tbody", make([]dom.Element, 0, len(a.rows)))
+	for _, r := range a.rows {
+		row, err := a.evalRow(r, "td", opt)
+		if err != nil {
+			return "", err
+		}
+		body.Contents = append(body.Contents, row)
+	}
+	table := dom.NewContentElement("table", []dom.Element{
+		dom.NewContentElement("thead", []dom.Element{head}),
+	})
+	if len(body.Contents) > 0 {
+		table.Contents = append(table.Contents, body)
+	}
+	return table.Render(), nil
+}
+
+func (a *astTable) evalRow(cells []*astParagraph, tag string, opt *Option) (dom.Element, *ParseError) {
+	row := dom.NewContentElement("tr", make([]dom.Element, 0, len(cells)))
+	for i, c := range cells {
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return nil, err
+		}
+		cell := dom.NewLiteralContentElement(tag, template.HTML(strings.TrimSpace(string(ct))))
+		if a.align[i] != alignNone {
+			cell.SetAttribute("data-align", string(a.align[i]))
+		}
+		row.Contents = append(row.Contents, cell)
+	}
+	return row, nil
+}
+
+func (a *astTable) Kind() NodeKind {
+	return KindTable
+}
+
+// Children returns the rows of the table, starting with the header.
+func (a *astTable) Children() []Node {
+	res := make([]Node, 0, len(a.rows)+1)
+	res = append(res, &astTableRow{cells: a.header, align: a.align, header: true})
+	for _, r := range a.rows {
+		res = append(res, &astTableRow{cells: r, align: a.align})
+	}
+	return res
+}
+
+func (a *astTable) Attr(string) string {
+	return ""
+}
+
+type astTableRow struct {
+	cells  []*astParagraph
+	align  []tableAlign
+	header bool
+}
+
+func (a *astTableRow) Kind() NodeKind {
+	return KindTableRow
+}
+
+func (a *astTableRow) Children() []Node {
+	res := make([]Node, len(a.cells))
+	for i, c := range a.cells {
+		res[i] = &astTableCell{content: c, align: a.align[i], header: a.header}
+	}
+	return res
+}
+
+func (a *astTableRow) Attr(key string) string {
+	if key == "header" && a.header {
+		return "true"
+	}
+	return ""
+}
+
+type astTableCell struct {
+	content *astParagraph
+	align   tableAlign
+	header  bool
+}
+
+func (a *astTableCell) Kind() NodeKind {
+	return KindTableCell
+}
+
+func (a *astTableCell) Children() []Node {
+	return a.content.Children()
+}
+
+func (a *astTableCell) Attr(key string) string {
+	switch key {
+	case "align":
+		return string(a.align)
+	case "header":
+		if a.header {
+			return "true"
+		}
+	}
+	return ""
+}
+
+func table(lxs *lexers) (block, *ParseError) {
+	tree := new(astTable)
+	for {
+		raw := tableRow(lxs)
+		switch {
+		case tree.header == nil:
+			cells, err := tableCells(lxs, raw)
+			if err != nil {
+				return nil, err
+			}
+			tree.header = cells
+		case tree.align == nil:
+			align, err := tableDelimiter(lxs, raw, len(tree.header))
+			if err != nil {
+				return nil, err
+			}
+			tree.align = align
+		default:
+			if len(raw) != len(tree.header) {
+				return nil, &ParseError{lxs: *lxs, internal: ErrInvalidTableRow}
+			}
+			cells, err := tableCells(lxs, raw)
+			if err != nil {
+				return nil, err
+			}
+			tree.rows = append(tree.rows, cells)
+		}
+		// current is the break ending the row
+		if lxs.Finished() || len(lxs.Current().Value) > 1 {
+			break
+		}
+		if !lxs.Next() {
+			break
+		}
+		if lxs.Current().Type != lexerTable {
+			lxs.Before() // because we did not use it
+			break
+		}
+	}
+	if tree.align == nil {
+		return nil, &ParseError{lxs: *lxs, internal: ErrInvalidTableDelimiter}
+	}
+	return tree, nil
+}
+
+// tableRow splits the row starting at the current pipe into the lexers of each cell.
+// When it returns, the current lexer is the break ending the row.
+func tableRow(lxs *lexers) [][]lexer {
+	var cells [][]lexer
+	var tokens []lexer
+	inCode := false
+	for lxs.Next() && lxs.Current().Type != lexerBreak {
+		current := lxs.Current()
+		if current.Type == lexerCode && len(current.Value) == 1 {
+			inCode = !inCode
+		}
+		if current.Type != lexerTable || inCode {
+			tokens = append(tokens, current)
+			continue
+		}
+		cells = append(cells, tokens)
+		tokens = nil
+	}
+	// trailing pipe is optional
+	if len(strings.TrimSpace(lexersValue(tokens))) > 0 {
+		cells = append(cells, tokens)
+	}
+	return cells
+}
+
+func tableCells(lxs *lexers, raw [][]lexer) ([]*astParagraph, *ParseError) {
+	cells := make([]*astParagraph, len(raw))
+	for i, tokens := range raw {
+		if len(tokens) == 0 {
+			cells[i] = &astParagraph{oneLine: true}
+			continue
+		}
+		var err *ParseError
+		cells[i], err = paragraph(&lexers{lexers: tokens, warnings: lxs.warnings}, true)
+		if err != nil {
+			return nil, &ParseError{lxs: *lxs, internal: err.internal}
+		}
+	}
+	return cells, nil
+}
+
+func tableDelimiter(lxs *lexers, raw [][]lexer, n int) ([]tableAlign, *ParseError) {
+	if len(raw) != n {
+		return nil, &ParseError{lxs: *lxs, internal: ErrInvalidTableDelimiter}
+	}
+	align := make([]tableAlign, n)
+	for i, tokens := range raw {
+		v := strings.TrimSpace(lexersValue(tokens))
+		if !regexTableDelimiter.MatchString(v) {
+			return nil, &ParseError{lxs: *lxs, internal: ErrInvalidTableDelimiter}
+		}
+		left := strings.HasPrefix(v, ":")
+		right := strings.HasSuffix(v, ":")
+		switch {
+		case left && right:
+			align[i] = alignCenter
+		case left:
+			align[i] = alignLeft
+		case right:
+			align[i] = alignRight
+		}
+	}
+	return align, nil
+}
+
+func lexersValue(tokens []lexer) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.Value)
+	}
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var rawTable = `
+| Nom | **Langage** | Lignes |
+|:----|:-----------:|-------:|
+| [small-web](https://tangled.org/anhgelus.world/small-web) | ` + "`Go|SCSS`" + ` | *3739* |
+| typdown | | 42 |
+`
+
+var expectedTable = `
+<table>
+<thead><tr><th data-align="left">Nom</th><th data-align="center"><b>Langage</b></th><th data-align="right">Lignes</th></tr></thead>
+<tbody>
+<tr>
+<td data-align="left"><a href="https://tangled.org/anhgelus.world/small-web" target="_blank" rel="noreferer">small-web</a></td>
+<td data-align="center"><code>Go|SCSS</code></td>
+<td data-align="right"><em>3739</em></td>
+</tr>
+<tr><td data-align="left">typdown</td><td data-align="center"></td><td data-align="right">42</td></tr>
+</tbody>
+</table>
+`
+
+func TestTable(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		t.Run("simple", test("| a | b |\n| - | - |\n| 1 | 2 |", `<table><thead><tr><th>a</th><th>b</th></tr></thead>`+
+			`<tbody><tr><td>1</td><td>2</td></tr></tbody></table>`))
+		t.Run("header_only", test("| a | b |\n| --- | --- |", `<table><thead><tr><th>a</th><th>b</th></tr></thead></table>`))
+		t.Run("combo", test(rawTable, strings.ReplaceAll(expectedTable, "\n", "")))
+		t.Run("after_paragraph", test("avant\n| a |\n| - |\n\naprès", `<p>avant</p>`+
+			`<table><thead><tr><th>a</th></tr></thead></table><p>après</p>`))
+		t.Run("pipe_in_paragraph", test("a | b", `<p>a | b</p>`))
+	})
+	t.Run("errors", func(t *testing.T) {
+		fn := func(input string, expected error) func(*testing.T) {
+			return func(t *testing.T) {
+				t.Parallel()
+				v, err := Parse(input, nil)
+				if err == nil {
+					t.Fatalf("expected error, got %s", v)
+				}
+				if !errors.Is(err.internal, expected) {
+					t.Errorf("invalid error, got %v", err)
+				}
+				t.Log(err.Pretty())
+			}
+		}
+		t.Run("no_delimiter", fn("| a | b |\nbonsoir", ErrInvalidTableDelimiter))
+		t.Run("invalid_delimiter", fn("| a | b |\n| - | x |", ErrInvalidTableDelimiter))
+		t.Run("missing_cell", fn("| a | b |\n| - | - |\n| 1 |", ErrInvalidTableRow))
+		t.Run("extra_cell", fn("| a | b |\n| - | - |\n| 1 | 2 | 3 |", ErrInvalidTableRow))
+	})
+}
@@ -35,7 +35,7 @@ Elle est **magnifique**, n'est-ce pas ?
 `
 
 var parsed = `
-<h1>Je suis un titre</h1>
+<h1 id="je-suis-un-titre">Je suis un titre</h1>
 <p>Avec une description classique, sur plusieurs lignes !</p>
 <p>Et je peux mettre du texte en <b>gras</b>, en <em>italique</em> et les <b><em>deux en même temps</em></b> !</p>
 <div class="quote"><blockquote>Je suis une magnifique citation sur plusieurs lignes</blockquote><p>avec une source</p></div>
@@ -45,13 +45,13 @@ var parsed = `
 <ol><li>et maintenant</li><li>elle l&#39;est</li></ol>
 <ul><li>hehe</li></ul>
 <figure>
-<img alt="Ceci est ma pfp :3" src="https://cdn.anhgelus.world/pfp.jpg">
+<img alt="Ceci est ma pfp :3" src="https://cdn.anhgelus.world/pfp.jpg" loading="lazy">
 <figcaption><a href="https://now.anhgelus.world/" target="_blank" rel="noreferer">Ma pfp</a> hehe :D Elle est <b>magnifique</b>, n&#39;est-ce pas ?</figcaption>
 </figure>
 `
 
 var parsedPoem = `
-<h1>Je suis un titre</h1>
+<h1 id="je-suis-un-titre">Je suis un titre</h1>
 <p>Avec une description classique,<br>sur plusieurs lignes !</p>
 <p>Et je peux mettre du texte en <b>gras</b>,<br>en <em>italique</em> et les <b><em>deux en même temps</em></b> !</p>
 <div class="quote"><blockquote>Je suis une magnifique citation sur plusieurs lignes</blockquote><p>avec une source</p></div>
@@ -61,7 +61,7 @@ var parsedPoem = `
 <ol><li>et maintenant</li><li>elle l&#39;est</li></ol>
 <ul><li>hehe</li></ul>
 <figure>
-<img alt="Ceci est ma pfp :3" src="https://cdn.anhgelus.world/pfp.jpg">
+<img alt="Ceci est ma pfp :3" src="https://cdn.anhgelus.world/pfp.jpg" loading="lazy">
 <figcaption><a href="https://now.anhgelus.world/" target="_blank" rel="noreferer">Ma pfp</a> hehe :D Elle est <b>magnifique</b>, n&#39;est-ce pas ?</figcaption>
 </figure>
 `
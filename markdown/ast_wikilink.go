@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var ErrDanglingWikiLink = errors.New("dangling wiki link")
+
+// astWikiLink is a link written [[target]] or [[target|label]], resolved by Option.WikiLink.
+type astWikiLink struct {
+	target string
+	label  string
+	lxs    lexers
+}
+
+func (a *astWikiLink) Eval(opt *Option) (template.HTML, *ParseError) {
+	href, title, ok := a.resolve(opt)
+	if !ok {
+		err := &ParseError{lxs: a.lxs, internal: errors.Join(ErrDanglingWikiLink, fmt.Errorf("target: %s", a.target))}
+		if !opt.warnings.recover(err) {
+			return "", err
+		}
+		return template.HTML(template.HTMLEscapeString(a.content(title))), nil
+	}
+	return opt.RenderLink(template.HTMLEscapeString(a.content(title)), href), nil
+}
+
+// resolve returns the URL and the title of the target.
+// The fragment of the target is kept in the URL.
+func (a *astWikiLink) resolve(opt *Option) (string, string, bool) {
+	target, fragment, _ := strings.Cut(a.target, "#")
+	href, title, ok := opt.WikiLink(target)
+	if ok && len(fragment) > 0 {
+		href += "#" + fragment
+	}
+	return href, title, ok
+}
+
+// content returns the text of the link.
+func (a *astWikiLink) content(title string) string {
+	switch {
+	case len(a.label) > 0:
+		return a.label
+	case len(title) > 0:
+		return title
+	default:
+		return a.target
+	}
+}
+
+func (a *astWikiLink) Kind() NodeKind {
+	return KindWikiLink
+}
+
+func (a *astWikiLink) Children() []Node {
+	return nil
+}
+
+func (a *astWikiLink) Attr(key string) string {
+	switch key {
+	case "target":
+		return a.target
+	case "label":
+		return a.label
+	default:
+		return ""
+	}
+}
+
+func wikiLink(lxs *lexers) *astWikiLink {
+	v := lxs.Current().Value
+	target, label, _ := strings.Cut(v[2:len(v)-2], "|")
+	return &astWikiLink{target: strings.TrimSpace(target), label: strings.TrimSpace(label), lxs: *lxs}
+}
+
+// wikiLinkLength returns the length of the wiki link starting at i.
+// It returns 0 if it is not closed on the same line or if its target is empty.
+func wikiLinkLength(runes []rune, i int) int {
+	if i+1 >= len(runes) || runes[i+1] != '[' {
+		return 0
+	}
+	for j := i + 2; j < len(runes)-1 && runes[j] != '\n' && runes[j] != '['; j++ {
+		if runes[j] != ']' {
+			continue
+		}
+		target, _, _ := strings.Cut(string(runes[i+2:j]), "|")
+		if runes[j+1] != ']' || len(strings.TrimSpace(target)) == 0 {
+			return 0
+		}
+		return j + 2 - i
+	}
+	return 0
+}
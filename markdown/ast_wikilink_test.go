@@ -0,0 +1,44 @@
+package markdown
+
+import "testing"
+
+func wikiLinkOption() *Option {
+	return &Option{WikiLink: func(target string) (string, string, bool) {
+		if target == "logs/bonsoir" {
+			return "/logs/bonsoir", "Bonsoir", true
+		}
+		return "", "", false
+	}}
+}
+
+func TestWikiLink(t *testing.T) {
+	t.Run("wiki link", func(t *testing.T) {
+		t.Run("title", testWithOptions(wikiLinkOption(), "Voir [[logs/bonsoir]].",
+			`<p>Voir <a href="/logs/bonsoir">Bonsoir</a>.</p>`))
+		t.Run("label", testWithOptions(wikiLinkOption(), "[[logs/bonsoir|ce log]]",
+			`<p><a href="/logs/bonsoir">ce log</a></p>`))
+		t.Run("fragment", testWithOptions(wikiLinkOption(), "[[logs/bonsoir#fin]]",
+			`<p><a href="/logs/bonsoir#fin">Bonsoir</a></p>`))
+		t.Run("modifier", testWithOptions(wikiLinkOption(), "*[[logs/bonsoir]]*",
+			`<p><em><a href="/logs/bonsoir">Bonsoir</a></em></p>`))
+	})
+	t.Run("dangling", func(t *testing.T) {
+		_, err := Parse("[[logs/inconnu]]", wikiLinkOption())
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		opt := wikiLinkOption()
+		opt.Lenient = true
+		doc, err := ParseDocument("[[logs/inconnu|lien]]", opt)
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		res, err := doc.HTML()
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		if string(res) != `<p>lien</p>` || len(doc.Warnings()) != 1 {
+			t.Errorf("invalid value, got %s %v", res, doc.Warnings())
+		}
+	})
+}
@@ -1,14 +1,90 @@
 package markdown
 
-import "fmt"
+import (
+	"fmt"
+	"unicode/utf8"
+)
 
 type ParseError struct {
 	internal error
 	lxs      lexers
+	file     string
+	// offset is the number of lines before the source in file
+	offset int
 }
 
 func (e *ParseError) Error() string {
-	return e.internal.Error()
+	line, column := e.Line(), e.Column()
+	switch {
+	case line == 0:
+		if len(e.file) == 0 {
+			return e.internal.Error()
+		}
+		return fmt.Sprintf("%s: %v", e.file, e.internal)
+	case len(e.file) == 0:
+		return fmt.Sprintf("%d:%d: %v", line, column, e.internal)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %v", e.file, line, column, e.internal)
+	}
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.internal
+}
+
+// File returns the path of the file containing the error, if known.
+func (e *ParseError) File() string {
+	return e.file
+}
+
+// Line returns the line of the error, starting at 1.
+// It returns 0 if the position is unknown.
+func (e *ParseError) Line() int {
+	lx, ok := e.lexer()
+	if !ok || lx.Line == 0 {
+		return 0
+	}
+	return lx.Line + e.offset
+}
+
+// Column returns the column of the error in runes, starting at 1.
+// It returns 0 if the position is unknown.
+func (e *ParseError) Column() int {
+	lx, ok := e.lexer()
+	if !ok {
+		return 0
+	}
+	return lx.Column
+}
+
+func (e *ParseError) lexer() (lexer, bool) {
+	l := e.lxs.lexers
+	if len(l) == 0 {
+		return lexer{}, false
+	}
+	return l[max(0, min(e.lxs.current, len(l)-1))], true
+}
+
+// warnings collects the errors recovered in lenient mode.
+type warnings []*ParseError
+
+// recover stores err and returns true if the mode is lenient, i.e. if w is not nil.
+func (w *warnings) recover(err *ParseError) bool {
+	if w == nil {
+		return false
+	}
+	*w = append(*w, err)
+	return true
+}
+
+// withFile sets the file of the error from the Option.
+func (e *ParseError) withFile(opt *Option) *ParseError {
+	if e == nil {
+		return nil
+	}
+	e.file = opt.File
+	e.offset = opt.LineOffset
+	return e
 }
 
 func (e *ParseError) Pretty() string {
@@ -29,7 +105,7 @@ func (e *ParseError) Pretty() string {
 			if lxs.current == current {
 				ch = "^"
 			}
-			for range len(lxs.Current().Value) {
+			for range utf8.RuneCountInString(lxs.Current().Value) {
 				ind += ch
 			}
 		}
@@ -39,5 +115,10 @@ func (e *ParseError) Pretty() string {
 		runes[len(runes)-1] = '^'
 		ind = string(runes)
 	}
-	return fmt.Sprintf("%v\n\n%s\n%s", e, contxt, ind)
+	line := e.Line()
+	if line == 0 {
+		return fmt.Sprintf("%v\n\n%s\n%s", e, contxt, ind)
+	}
+	gutter := fmt.Sprintf("%d | ", line)
+	return fmt.Sprintf("%v\n\n%s%s\n%*s%s", e, gutter, contxt, len(gutter), "| ", ind)
 }
@@ -1,6 +1,9 @@
 package markdown
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestError(t *testing.T) {
 	v, err := Parse("**bonsoir", nil)
@@ -24,3 +27,86 @@ func TestError(t *testing.T) {
 		t.Log(err.Pretty())
 	}
 }
+
+func TestErrorPosition(t *testing.T) {
+	fn := func(s string, opt *Option, line, column int, msg string) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(s, opt)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Line() != line || err.Column() != column {
+				t.Errorf("invalid position, got %d:%d", err.Line(), err.Column())
+			}
+			if err.Error() != msg {
+				t.Errorf("invalid message, got %s", err.Error())
+			}
+			t.Log(err.Pretty())
+		}
+	}
+	t.Run("position", func(t *testing.T) {
+		t.Run("first line", fn("test ``` hehe", nil, 1, 6,
+			"1:6: invalid paragraph\ninvalid code block position"))
+		t.Run("third line", fn("# Titre\n\nUn *pro*blè**me", nil, 3, 14,
+			"3:14: invalid paragraph\ninvalid modifier usage"))
+		t.Run("file", fn("Bonsoir\n[^x]: rien", &Option{File: "log.md", LineOffset: 10}, 12, 1,
+			"log.md:12:1: invalid footnote\nunused footnote\nfootnote: x"))
+	})
+}
+
+func TestLenient(t *testing.T) {
+	fn := func(s, expected string, warnings int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Parallel()
+			doc, err := ParseDocument(s, &Option{Lenient: true})
+			if err != nil {
+				t.Fatal(err.Pretty())
+			}
+			res, err := doc.HTML()
+			if err != nil {
+				t.Fatal(err.Pretty())
+			}
+			if string(res) != expected {
+				t.Errorf("invalid value, got %s", res)
+			}
+			if len(doc.Warnings()) != warnings {
+				t.Errorf("invalid number of warnings, got %v", doc.Warnings())
+			}
+		}
+	}
+	t.Run("lenient", func(t *testing.T) {
+		t.Run("valid", fn("*bonsoir*", "<p><em>bonsoir</em></p>", 0))
+		t.Run("modifier", fn("**bonsoir", "<p>**bonsoir</p>", 1))
+		t.Run("modifier after", fn("un *pro*blè**me", "<p>un <em>pro</em>blè**me</p>", 1))
+		t.Run("code", fn("test ``` hehe", "<p>test ``` hehe</p>", 1))
+		t.Run("inline code", fn("un `code\nsur` deux", "<p>un `code sur` deux</p>", 2))
+		t.Run("footnote", fn("a[^x]\n\n[^y]: b", "<p>a[^x]</p>", 2))
+		t.Run("lone modifier", fn("**", "<p>**</p>", 1))
+		t.Run("nested modifier", fn("é_*", "<p>é_*</p>", 2))
+		t.Run("link modifier", fn("[[[_", "<p>[[[_</p>", 1))
+	})
+	t.Run("footnote order", func(t *testing.T) {
+		doc, err := ParseDocument("a\n\n[^c]: x\n\n[^a]: y\n\n[^b]: z", &Option{Lenient: true})
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		if _, err = doc.HTML(); err != nil {
+			t.Fatal(err.Pretty())
+		}
+		var got []string
+		for _, w := range doc.Warnings() {
+			got = append(got, w.Error())
+		}
+		for i, id := range []string{"c", "a", "b"} {
+			if i >= len(got) || !strings.HasSuffix(got[i], "footnote: "+id) {
+				t.Fatalf("invalid warnings, got %v", got)
+			}
+		}
+	})
+	for _, s := range []string{"**bonsoir", "**", "é_*", "[[[_"} {
+		if _, err := Parse(s, nil); err == nil {
+			t.Errorf("expected error in strict mode for %q", s)
+		}
+	}
+}
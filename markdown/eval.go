@@ -2,36 +2,84 @@ package markdown
 
 import (
 	"html/template"
+
+	"anhgelus.world/small-web/dom"
 )
 
 type Option struct {
 	ImageSource func(source string) string
-	RenderLink  func(content, href string) template.HTML
-	Replaces    map[rune]string
-	Poem        bool
+	// ResponsiveImage returns the variants of the image, before ImageSource.
+	// It can be nil, like its result.
+	ResponsiveImage func(source string) *dom.ResponsiveImg
+	// ImageSizes is the sizes attribute of responsive images.
+	ImageSizes string
+	RenderLink func(content, href string) template.HTML
+	// Replaces are the substitutions of the text, applied outside of code.
+	Replaces []Replacer
+	Poem     bool
+	// File is the path of the parsed file, used by ParseError.
+	File string
+	// LineOffset is the number of lines before the source in File, like a front matter.
+	LineOffset int
+	// Renderer used by Render.
+	// Parse always renders HTML.
+	Renderer Renderer
+	// Lenient recovers from malformed inline constructs, rendering them as literal text.
+	// Recovered errors are available with Document.Warnings.
+	Lenient bool
+	// HTML is the allowlist of raw HTML elements, associated with their allowed attributes.
+	// Raw HTML is escaped if it is nil.
+	// See DefaultHTML.
+	HTML map[string][]string
+	// Directives are the directives available, by name.
+	// Directives are not parsed if it is nil.
+	Directives map[string]Directive
+	// WikiLink returns the URL and the title of the target of a wiki link, written [[target]] or [[target|label]].
+	// It returns false if the target does not exist.
+	// Wiki links are not parsed if it is nil.
+	WikiLink func(target string) (href, title string, ok bool)
+	// Typography is the language of the typographic replacements, like curly quotes or the non-breaking spaces
+	// before the punctuation in French.
+	// Only English (en) and French (fr) are supported, and there are no replacements if it is empty.
+	Typography string
+	warnings   *warnings
+	footnotes  *footnotes
+	outline    *Outline
+	typography *typography
+	html       *htmlStack
 }
 
 func Parse(s string, opt *Option) (template.HTML, *ParseError) {
-	if opt == nil {
-		opt = new(Option)
-	}
-	if opt.ImageSource == nil {
-		opt.ImageSource = func(s string) string { return s }
-	}
-	if opt.RenderLink == nil {
-		opt.RenderLink = RenderLink
-	}
-	if opt.Replaces == nil {
-		opt.Replaces = make(map[rune]string, 0)
+	doc, err := ParseDocument(s, opt)
+	if err != nil {
+		return "", err
 	}
-	lxs := lex(s, opt)
-	tree, err := ast(lxs)
+	return doc.HTML()
+}
+
+// Render parses the markdown and renders it with Option.Renderer.
+// If it is nil, the markdown is rendered to HTML.
+func Render(s string, opt *Option) (string, *ParseError) {
+	doc, err := ParseDocument(s, opt)
 	if err != nil {
 		return "", err
 	}
-	return tree.Eval(opt)
+	return doc.Render()
 }
 
 func ParseBytes(b []byte, opt *Option) (template.HTML, *ParseError) {
 	return Parse(string(b), opt)
 }
+
+// ParseWithOutline parses the markdown and returns the Outline of the document.
+func ParseWithOutline(s string, opt *Option) (template.HTML, Outline, *ParseError) {
+	doc, err := ParseDocument(s, opt)
+	if err != nil {
+		return "", nil, err
+	}
+	res, err := doc.HTML()
+	if err != nil {
+		return "", nil, err
+	}
+	return res, doc.Outline(), nil
+}
@@ -0,0 +1,202 @@
+package markdown
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"anhgelus.world/small-web/dom"
+)
+
+type highlightClass string
+
+const (
+	hlNone     highlightClass = ""
+	hlKeyword  highlightClass = "hl-keyword"
+	hlType     highlightClass = "hl-type"
+	hlBuiltin  highlightClass = "hl-builtin"
+	hlString   highlightClass = "hl-string"
+	hlNumber   highlightClass = "hl-number"
+	hlComment  highlightClass = "hl-comment"
+	hlFunction highlightClass = "hl-function"
+	hlVariable highlightClass = "hl-variable"
+	hlTag      highlightClass = "hl-tag"
+	hlAttr     highlightClass = "hl-attr"
+	hlProperty highlightClass = "hl-property"
+	hlPunct    highlightClass = "hl-punct"
+)
+
+type highlightRule struct {
+	class highlightClass
+	regex *regexp.Regexp
+	// followedBy must match what comes after regex, without being consumed
+	followedBy *regexp.Regexp
+}
+
+func rule(class highlightClass, regex string) highlightRule {
+	return highlightRule{class: class, regex: regexp.MustCompile(`^(?:` + regex + `)`)}
+}
+
+func ruleFollowed(class highlightClass, regex, followedBy string) highlightRule {
+	r := rule(class, regex)
+	r.followedBy = regexp.MustCompile(`^(?:` + followedBy + `)`)
+	return r
+}
+
+func (r highlightRule) match(s string) int {
+	loc := r.regex.FindStringIndex(s)
+	if loc == nil {
+		return 0
+	}
+	if r.followedBy != nil && !r.followedBy.MatchString(s[loc[1]:]) {
+		return 0
+	}
+	return loc[1]
+}
+
+func words(w ...string) string {
+	return `(?:` + strings.Join(w, "|") + `)\b`
+}
+
+var (
+	goRules = []highlightRule{
+		rule(hlComment, `//[^\n]*|/\*(?s:.*?)\*/`),
+		rule(hlString, "`[^`]*`"+`|"(?:\\.|[^"\\\n])*"|'(?:\\.|[^'\\\n])*'`),
+		rule(hlKeyword, words(
+			"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+			"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+			"switch", "type", "var",
+		)),
+		rule(hlType, words(
+			"any", "bool", "byte", "comparable", "complex64", "complex128", "error", "float32", "float64", "int",
+			"int8", "int16", "int32", "int64", "rune", "string", "uint", "uint8", "uint16", "uint32", "uint64",
+			"uintptr",
+		)),
+		rule(hlBuiltin, words(
+			"true", "false", "nil", "iota", "append", "cap", "clear", "close", "copy", "delete", "len", "make",
+			"max", "min", "new", "panic", "print", "println", "recover",
+		)),
+		ruleFollowed(hlFunction, `[\pL_][\pL\pN_]*`, `\(`),
+		rule(hlNone, `[\pL_][\pL\pN_]*`),
+		rule(hlNumber, `0[xX][0-9a-fA-F_]+|\d[\d_]*(?:\.\d+)?(?:[eE][+-]?\d+)?`),
+	}
+	shellRules = []highlightRule{
+		rule(hlComment, `#[^\n]*`),
+		rule(hlString, `"(?:\\.|[^"\\])*"|'[^']*'`),
+		rule(hlVariable, `\$(?:\{[^}]*\}|[\pL_][\pL\pN_]*|[0-9@#?*!$-])`),
+		rule(hlKeyword, words(
+			"if", "then", "else", "elif", "fi", "for", "while", "until", "do", "done", "case", "esac", "in",
+			"function", "return", "local", "export",
+		)),
+		rule(hlBuiltin, words(
+			"cd", "echo", "printf", "read", "set", "unset", "source", "exit", "test", "sudo", "alias",
+		)),
+		rule(hlNone, `[\pL\pN_.-]+`),
+		rule(hlPunct, `&&|\|\||[|;&<>]`),
+	}
+	tomlRules = []highlightRule{
+		rule(hlComment, `#[^\n]*`),
+		rule(hlTag, `\[\[[^\]\n]*\]\]|\[[^\]\n]*\]`),
+		rule(hlString, `"""(?s:.*?)"""|'''(?s:.*?)'''|"(?:\\.|[^"\\\n])*"|'[^'\n]*'`),
+		rule(hlBuiltin, words("true", "false", "inf", "nan")),
+		rule(hlNumber, `\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})?)?`+
+			`|[+-]?\d[\d_]*(?:\.\d+)?(?:[eE][+-]?\d+)?`),
+		rule(hlProperty, `[\pL\pN_-]+(?:\.[\pL\pN_-]+)*`),
+	}
+	htmlRules = []highlightRule{
+		rule(hlComment, `<!--(?s:.*?)-->`),
+		rule(hlTag, `</?[a-zA-Z][\w:-]*|/?>|<!(?i:doctype)`),
+		rule(hlString, `"[^"]*"|'[^']*'`),
+		ruleFollowed(hlAttr, `[a-zA-Z_:][\w:.-]*`, `=`),
+		rule(hlNone, `[^<>"'=\s]+`),
+	}
+	cssRules = []highlightRule{
+		rule(hlComment, `/\*(?s:.*?)\*/`),
+		rule(hlString, `"(?:\\.|[^"\\\n])*"|'(?:\\.|[^'\\\n])*'`),
+		rule(hlKeyword, `@[\w-]+|!important`),
+		ruleFollowed(hlProperty, `--[a-zA-Z][\w-]*|[a-zA-Z][\w-]*`, `\s*:[^{};]*(?:;|}|$)`),
+		rule(hlNumber, `#[0-9a-fA-F]{3,8}\b|-?\d*\.?\d+(?:%|[a-zA-Z]+)?`),
+		ruleFollowed(hlFunction, `[a-zA-Z][\w-]*`, `\(`),
+		rule(hlNone, `[\w-]+`),
+	}
+	jsonRules = []highlightRule{
+		ruleFollowed(hlProperty, `"(?:\\.|[^"\\\n])*"`, `\s*:`),
+		rule(hlString, `"(?:\\.|[^"\\\n])*"`),
+		rule(hlBuiltin, words("true", "false", "null")),
+		rule(hlNumber, `-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?`),
+		rule(hlPunct, `[{}\[\],:]`),
+	}
+)
+
+var highlightLanguages = map[string][]highlightRule{
+	"go":     goRules,
+	"golang": goRules,
+	"sh":     shellRules,
+	"shell":  shellRules,
+	"bash":   shellRules,
+	"zsh":    shellRules,
+	"toml":   tomlRules,
+	"html":   htmlRules,
+	"xml":    htmlRules,
+	"css":    cssRules,
+	"scss":   cssRules,
+	"json":   jsonRules,
+}
+
+// codeLanguage returns the language declared in the info string of a fenced code block.
+// It returns an empty string if the language contains other runes than letters, digits, +, # and -.
+func codeLanguage(before string) string {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return ""
+	}
+	for _, c := range fields[0] {
+		if !strings.ContainsRune("+#-", c) && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			return ""
+		}
+	}
+	return strings.ToLower(fields[0])
+}
+
+// highlight returns the escaped content with spans annotated by highlightClass.
+// If the language is not supported, content is only escaped.
+func highlight(lang, content string) template.HTML {
+	rules, ok := highlightLanguages[lang]
+	if !ok {
+		return template.HTML(template.HTMLEscapeString(content))
+	}
+	var sb strings.Builder
+	var plain strings.Builder
+	flush := func() {
+		sb.WriteString(template.HTMLEscapeString(plain.String()))
+		plain.Reset()
+	}
+	for s := content; len(s) > 0; {
+		matched := false
+		for _, r := range rules {
+			n := r.match(s)
+			if n == 0 {
+				continue
+			}
+			matched = true
+			if r.class == hlNone {
+				plain.WriteString(s[:n])
+			} else {
+				flush()
+				span := dom.NewLiteralContentElement("span", template.HTML(template.HTMLEscapeString(s[:n])))
+				span.ClassList().Add(string(r.class))
+				sb.WriteString(string(span.Render()))
+			}
+			s = s[n:]
+			break
+		}
+		if !matched {
+			_, size := utf8.DecodeRuneInString(s)
+			plain.WriteString(s[:size])
+			s = s[size:]
+		}
+	}
+	flush()
+	return template.HTML(sb.String())
+}
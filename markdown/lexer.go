@@ -3,12 +3,15 @@ package markdown
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 type lexerType string
 
 const (
 	lexerBreak lexerType = "break"
+	// lexerHardBreak is a backslash or at least two spaces ending a line
+	lexerHardBreak lexerType = "hard_break"
 
 	lexerModifier lexerType = "modifier"
 
@@ -17,9 +20,17 @@ const (
 	lexerHeading lexerType = "header"
 	lexerQuote   lexerType = "quote"
 	lexerList    lexerType = "list"
+	lexerTable   lexerType = "table"
 
 	lexerExternal lexerType = "external"
 	lexerCallout  lexerType = "callout"
+	lexerFootnote lexerType = "footnote"
+
+	lexerHTML lexerType = "html"
+	lexerMath lexerType = "math"
+	// lexerDirective is a shortcode or a line starting with a directive fence
+	lexerDirective lexerType = "directive"
+	lexerWikiLink  lexerType = "wiki_link"
 
 	lexerLiteral lexerType = "literal"
 	lexerReplace lexerType = "replace"
@@ -28,6 +39,11 @@ const (
 type lexer struct {
 	Type  lexerType
 	Value string
+	// Line and Column of the first rune of Value in the source, starting at 1
+	Line   int
+	Column int
+	// Replace is the HTML replacing Value if it is a lexerReplace
+	Replace string
 }
 
 func (l lexer) String() string {
@@ -37,6 +53,8 @@ func (l lexer) String() string {
 type lexers struct {
 	current int
 	lexers  []lexer
+	// warnings is nil in strict mode
+	warnings *warnings
 }
 
 func (l *lexers) Next() bool {
@@ -76,33 +94,116 @@ func lex(s string, opt *Option) *lexers {
 	var lexs []lexer
 	var currentType lexerType
 	var previous string
+	// position of the current rune and of the first rune of previous
+	line, column := 1, 0
+	var start lexer
+	push := func() {
+		lexs = append(lexs, lexer{Type: currentType, Value: previous, Line: start.Line, Column: start.Column})
+		previous = ""
+	}
+	add := func(c rune) {
+		if len(previous) == 0 {
+			start.Line, start.Column = line, column
+		}
+		previous += string(c)
+	}
 	fn := func(c rune, t lexerType, validate func(rune) bool) {
 		if validate == nil {
 			validate = func(r rune) bool { return true }
 		}
 		if (currentType != t || !validate(c)) && len(previous) > 0 {
-			lexs = append(lexs, lexer{Type: currentType, Value: previous})
-			previous = ""
+			push()
 		}
 		currentType = t
-		previous += string(c)
+		add(c)
 	}
 	newLine := true
+	// lineStart is true if there are only spaces since the start of the line
+	lineStart := true
 	literalNext := false
+	// closing of the delimited modifiers, by position
+	closing := make(map[int]bool)
+	// number of runes already used
+	skip := 0
 	runes := []rune(s)
+	replaces := replacements(s, opt.Replaces)
 	for i, c := range runes {
+		column++
+		if i > 0 {
+			prev := runes[i-1]
+			lineStart = prev == '\n' || (lineStart && (prev == ' ' || prev == '\t'))
+			if prev == '\n' {
+				line++
+				column = 1
+			}
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
 		if literalNext {
 			fn(c, lexerLiteral, nil)
 			literalNext = false
 			continue
 		}
 		if c == '\\' {
+			if !lineStart && i < len(runes)-1 && runes[i+1] == '\n' {
+				fn(c, lexerHardBreak, nil)
+				continue
+			}
 			literalNext = true
 			continue
 		}
+		if c == '^' && currentType == lexerFootnote && previous == "[" {
+			fn(c, lexerFootnote, nil)
+			continue
+		}
+		if c == '<' || c == '$' || ((c == '{' || (c == ':' && lineStart)) && opt.Directives != nil) ||
+			(c == '[' && opt.WikiLink != nil) {
+			t, n := lexerHTML, 0
+			switch c {
+			case '<':
+				n = htmlTagLength(runes, i, opt.HTML)
+			case '$':
+				t, n = lexerMath, mathLength(runes, i)
+			case '{':
+				t, n = lexerDirective, shortcodeLength(runes, i)
+			case ':':
+				t, n = lexerDirective, fenceLength(runes, i)
+			case '[':
+				t, n = lexerWikiLink, wikiLinkLength(runes, i)
+			}
+			if n > 0 {
+				if len(previous) > 0 {
+					push()
+				}
+				currentType = t
+				for _, r := range runes[i : i+n] {
+					add(r)
+				}
+				push()
+				skip = n - 1
+				newLine = false
+				continue
+			}
+		}
+		if r, ok := replaces[i]; ok && replaceable(runes, i, lineStart, closing) {
+			if len(previous) > 0 {
+				push()
+			}
+			currentType = lexerReplace
+			for _, r := range runes[i : i+r.length] {
+				add(r)
+			}
+			push()
+			lexs[len(lexs)-1].Replace = r.replace
+			skip = r.length - 1
+			newLine = false
+			continue
+		}
 		switch c {
 		case '*':
-			if newLine && i < len(runes)-1 && runes[i+1] == ' ' {
+			if lineStart && i < len(runes)-1 && runes[i+1] == ' ' {
 				fn(c, lexerList, nil)
 				newLine = false
 				continue
@@ -112,20 +213,37 @@ func lex(s string, opt *Option) *lexers {
 			if (currentType != lexerModifier && len(previous) > 0) ||
 				(len(previous) > 0 && []rune(previous)[0] != c) ||
 				len(previous) >= 3 {
-				lexs = append(lexs, lexer{Type: currentType, Value: previous})
-				previous = ""
+				push()
 			}
 			currentType = lexerModifier
-			previous += string(c)
+			add(c)
 		case '`':
 			fn(c, lexerCode, nil)
 		case '\n':
+			if !lineStart && currentType == lexerLiteral && strings.HasSuffix(previous, "  ") {
+				content := strings.TrimRight(previous, " ")
+				spaces := len(previous) - len(content)
+				if len(content) > 0 {
+					previous = content
+					push()
+				}
+				previous = strings.Repeat(" ", spaces)
+				start.Line, start.Column = line, column-spaces
+				currentType = lexerHardBreak
+			}
 			fn(c, lexerBreak, nil)
 		case '#':
 			fn(c, lexerHeading, nil)
 		case '>':
 			fn(c, lexerQuote, nil)
+		case '|':
+			fn(c, lexerTable, func(c rune) bool { return false })
 		case '[', ']', '!':
+			if c == '[' && i < len(runes)-1 && runes[i+1] == '^' {
+				fn(c, lexerFootnote, nil)
+				newLine = false
+				continue
+			}
 			if !newLine && i < len(runes)-1 {
 				next := runes[i+1]
 				runes := []rune(previous)
@@ -148,24 +266,124 @@ func lex(s string, opt *Option) *lexers {
 			fallthrough
 		case '(', ')':
 			fn(c, lexerExternal, func(c rune) bool { return validExternal(previous + string(c)) })
-		case '-', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
 			fn(c, lexerList, nil)
-		default:
-			if _, ok := opt.Replaces[c]; ok {
-				fn(c, lexerReplace, func(c rune) bool { return false })
-			} else {
-				fn(c, lexerLiteral, nil)
+		case '~', '=', '^':
+			n := 0
+			if closing[i] {
+				n = runLength(runes, i)
+			} else if m, j := delimitedModifier(runes, i); m > 0 {
+				n = m
+				closing[j] = true
 			}
+			if n > 0 {
+				if len(previous) > 0 {
+					push()
+				}
+				currentType = lexerModifier
+				for range n {
+					add(c)
+				}
+				push()
+				skip = n - 1
+				newLine = false
+				continue
+			}
+			fallthrough
+		default:
+			fn(c, lexerLiteral, nil)
 		}
 		newLine = c == '\n'
 	}
 	if len(previous) > 0 {
-		lexs = append(lexs, lexer{Type: currentType, Value: previous})
+		push()
 	}
 	lxs.lexers = lexs
 	return lxs
 }
 
+// delimitedModifier returns the length of the modifier starting at i and the position of its closing.
+// It returns 0 if it is not a valid modifier closed on the same line.
+// Superscript and subscript cannot contain spaces, like in Pandoc.
+func delimitedModifier(runes []rune, i int) (int, int) {
+	c := runes[i]
+	if i > 0 && runes[i-1] == c {
+		return 0, 0
+	}
+	n := runLength(runes, i)
+	switch {
+	case c == '~' && (n == 1 || n == 2):
+	case c == '=' && n == 2:
+	case c == '^' && n == 1:
+	default:
+		return 0, 0
+	}
+	if i+n >= len(runes) || unicode.IsSpace(runes[i+n]) {
+		return 0, 0
+	}
+	for j := i + n; j < len(runes) && runes[j] != '\n'; j++ {
+		switch {
+		case runes[j] == '\\' && j+1 < len(runes) && runes[j+1] != '\n':
+			j++
+		case runes[j] == c:
+			m := runLength(runes, j)
+			if m == n && !unicode.IsSpace(runes[j-1]) {
+				return n, j
+			}
+			j += m - 1
+		case n == 1 && unicode.IsSpace(runes[j]):
+			return 0, 0
+		}
+	}
+	return 0, 0
+}
+
+// mathLength returns the length of the formula starting at i, with its delimiters.
+// It returns 0 if it is not a valid formula.
+// Like Pandoc, an inline formula cannot start or end with a space, and it cannot be followed by a digit.
+func mathLength(runes []rune, i int) int {
+	if i+1 < len(runes) && runes[i+1] == '$' {
+		for j := i + 2; j < len(runes)-1; j++ {
+			switch {
+			case runes[j] == '\\':
+				j++
+			case runes[j] == '$' && runes[j+1] == '$':
+				if j == i+2 {
+					return 0
+				}
+				return j + 2 - i
+			case runes[j] == '\n' && runes[j+1] == '\n':
+				// a display formula cannot contain an empty line
+				return 0
+			}
+		}
+		return 0
+	}
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return 0
+	}
+	for j := i + 1; j < len(runes) && runes[j] != '\n'; j++ {
+		switch {
+		case runes[j] == '\\':
+			j++
+		case runes[j] == '$':
+			if unicode.IsSpace(runes[j-1]) || (j+1 < len(runes) && unicode.IsDigit(runes[j+1])) {
+				continue
+			}
+			return j + 1 - i
+		}
+	}
+	return 0
+}
+
+func runLength(runes []rune, i int) int {
+	n := 0
+	for i+n < len(runes) && runes[i+n] == runes[i] {
+		n++
+	}
+	return n
+}
+
 func validExternal(s string) bool {
 	switch s {
 	// start
@@ -37,7 +37,7 @@ func TestLex(t *testing.T) {
 
 func TestLex_Replacer(t *testing.T) {
 	opt := &Option{
-		Replaces: map[rune]string{'~': "&thinsp;"},
+		Replaces: []Replacer{{Symbol: "~", Replace: "&thinsp;"}},
 	}
 	lxs := lex("bonjour les gens", opt)
 	if lxs.String() != "Lexers[literal(bonjour les gens) ]" {
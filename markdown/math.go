@@ -0,0 +1,525 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidMath        = errors.New("invalid math")
+	ErrUnknownMathCommand = errors.Join(ErrInvalidMath, errors.New("unknown command"))
+)
+
+var mathIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε", "zeta": "ζ",
+	"eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν",
+	"xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ",
+	"upsilon": "υ", "phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "emptyset": "∅", "hbar": "ℏ", "ell": "ℓ", "aleph": "ℵ",
+}
+
+// mathUprights are identifiers rendered upright, like uppercase Greek letters.
+var mathUprights = map[string]string{
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π", "Sigma": "Σ",
+	"Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+var mathOperators = map[string]string{
+	"times": "×", "cdot": "⋅", "pm": "±", "mp": "∓", "div": "÷", "ast": "∗", "star": "⋆", "circ": "∘",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈", "equiv": "≡",
+	"sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃", "supseteq": "⊇",
+	"cup": "∪", "cap": "∩", "setminus": "∖", "land": "∧", "wedge": "∧", "lor": "∨", "vee": "∨", "neg": "¬",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "leftrightarrow": "↔", "mapsto": "↦",
+	"Rightarrow": "⇒", "implies": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"forall": "∀", "exists": "∃", "mid": "∣", "parallel": "∥", "perp": "⊥", "angle": "∠",
+	"ldots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱", "prime": "′",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"{": "{", "}": "}", "|": "‖", "%": "%", "$": "$", "#": "#", "&": "&", "_": "_",
+}
+
+// mathLargeOperators are operators with limits, the bool is true if they are written under and over them.
+var mathLargeOperators = map[string]struct {
+	symbol string
+	limits bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true}, "bigcup": {"⋃", true}, "bigcap": {"⋂", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+}
+
+// mathFunctions are written upright, the bool is true if they have limits.
+var mathFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false, "arcsin": false,
+	"arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false, "log": false, "ln": false,
+	"exp": false, "det": false, "dim": false, "ker": false, "deg": false, "arg": false, "gcd": false,
+	"lim": true, "max": true, "min": true, "sup": true, "inf": true,
+}
+
+var mathSpaces = map[string]string{
+	",": "0.1667em", ":": "0.2222em", ">": "0.2222em", ";": "0.2778em", " ": "0.2778em",
+	"quad": "1em", "qquad": "2em",
+}
+
+var mathAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "‾", "vec": "→", "tilde": "~", "widetilde": "~",
+	"dot": "˙", "ddot": "¨",
+}
+
+var mathFences = map[string][2]string{
+	"matrix":  {"", ""},
+	"pmatrix": {"(", ")"},
+	"bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"},
+	"cases":   {"{", ""},
+	"aligned": {"", ""},
+}
+
+// mathML converts a subset of LaTeX into MathML Core.
+func mathML(tex string, display bool) (template.HTML, error) {
+	p := &mathParser{runes: []rune(tex)}
+	content, err := p.expr()
+	if err != nil {
+		return "", err
+	}
+	if !p.finished() {
+		return "", errors.Join(ErrInvalidMath, fmt.Errorf("unexpected %q", p.rest()))
+	}
+	var sb strings.Builder
+	sb.WriteString("<math")
+	if display {
+		sb.WriteString(` display="block"`)
+	}
+	sb.WriteString("><semantics><mrow>")
+	sb.WriteString(content)
+	sb.WriteString(`</mrow><annotation encoding="application/x-tex">`)
+	sb.WriteString(template.HTMLEscapeString(tex))
+	sb.WriteString("</annotation></semantics></math>")
+	return template.HTML(sb.String()), nil
+}
+
+type mathParser struct {
+	runes []rune
+	pos   int
+	// index is true when parsing the index of a root, ended by ]
+	index bool
+}
+
+func (p *mathParser) finished() bool {
+	return p.pos >= len(p.runes)
+}
+
+func (p *mathParser) rest() string {
+	return string(p.runes[p.pos:])
+}
+
+func (p *mathParser) skipSpaces() {
+	for !p.finished() && unicode.IsSpace(p.runes[p.pos]) {
+		p.pos++
+	}
+}
+
+// peekCommand returns the name of the command at the current position, without consuming it.
+func (p *mathParser) peekCommand() string {
+	if p.finished() || p.runes[p.pos] != '\\' || p.pos+1 >= len(p.runes) {
+		return ""
+	}
+	i := p.pos + 1
+	if !unicode.IsLetter(p.runes[i]) {
+		return string(p.runes[i])
+	}
+	for i < len(p.runes) && unicode.IsLetter(p.runes[i]) {
+		i++
+	}
+	return string(p.runes[p.pos+1 : i])
+}
+
+func (p *mathParser) command() string {
+	name := p.peekCommand()
+	p.pos += 1 + len([]rune(name))
+	return name
+}
+
+// expr parses atoms until the end of a group, of a cell or of the formula.
+func (p *mathParser) expr() (string, error) {
+	var sb strings.Builder
+	for {
+		p.skipSpaces()
+		if p.finished() {
+			return sb.String(), nil
+		}
+		switch c := p.runes[p.pos]; {
+		case c == '}' || c == '&' || (c == ']' && p.index):
+			return sb.String(), nil
+		case c == '\\':
+			switch p.peekCommand() {
+			case "\\", "right", "end":
+				return sb.String(), nil
+			}
+		}
+		atom, err := p.scripted()
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(atom)
+	}
+}
+
+// scripted parses an atom with its subscript and its superscript.
+func (p *mathParser) scripted() (string, error) {
+	base, limits, err := p.atom()
+	if err != nil {
+		return "", err
+	}
+	var sub, sup string
+	for {
+		p.skipSpaces()
+		if p.finished() {
+			break
+		}
+		c := p.runes[p.pos]
+		if c == '\'' {
+			p.pos++
+			sup += "<mo>′</mo>"
+			continue
+		}
+		if (c != '_' || len(sub) > 0) && (c != '^' || len(sup) > 0) {
+			break
+		}
+		p.pos++
+		p.skipSpaces()
+		var script string
+		if !p.finished() && unicode.IsDigit(p.runes[p.pos]) {
+			// like LaTeX, only the first digit is used
+			script = "<mn>" + string(p.runes[p.pos]) + "</mn>"
+			p.pos++
+		} else if script, _, err = p.atom(); err != nil {
+			return "", err
+		}
+		if c == '_' {
+			sub = script
+		} else {
+			sup = script
+		}
+	}
+	if strings.Count(sup, "<mo>′</mo>") > 1 {
+		sup = "<mrow>" + sup + "</mrow>"
+	}
+	tags := [3]string{"msub", "msup", "msubsup"}
+	if limits {
+		tags = [3]string{"munder", "mover", "munderover"}
+	}
+	switch {
+	case len(sub) > 0 && len(sup) > 0:
+		return fmt.Sprintf("<%s>%s%s%s</%s>", tags[2], base, sub, sup, tags[2]), nil
+	case len(sub) > 0:
+		return fmt.Sprintf("<%s>%s%s</%s>", tags[0], base, sub, tags[0]), nil
+	case len(sup) > 0:
+		return fmt.Sprintf("<%s>%s%s</%s>", tags[1], base, sup, tags[1]), nil
+	}
+	return base, nil
+}
+
+// atom parses one element, it returns true if its scripts are limits.
+func (p *mathParser) atom() (string, bool, error) {
+	if p.finished() {
+		return "", false, errors.Join(ErrInvalidMath, errors.New("missing argument"))
+	}
+	c := p.runes[p.pos]
+	switch {
+	case c == '{':
+		content, err := p.group()
+		return "<mrow>" + content + "</mrow>", false, err
+	case c == '\\':
+		return p.commandAtom()
+	case unicode.IsDigit(c) || (c == '.' && p.pos+1 < len(p.runes) && unicode.IsDigit(p.runes[p.pos+1])):
+		start := p.pos
+		for !p.finished() && (unicode.IsDigit(p.runes[p.pos]) ||
+			(p.runes[p.pos] == '.' && p.pos+1 < len(p.runes) && unicode.IsDigit(p.runes[p.pos+1]))) {
+			p.pos++
+		}
+		return "<mn>" + string(p.runes[start:p.pos]) + "</mn>", false, nil
+	case unicode.IsLetter(c):
+		p.pos++
+		return "<mi>" + template.HTMLEscapeString(string(c)) + "</mi>", false, nil
+	case c == '^' || c == '_':
+		// script without base
+		return "<mrow></mrow>", false, nil
+	case c == '}' || c == '&' || (c == ']' && p.index):
+		return "", false, errors.Join(ErrInvalidMath, fmt.Errorf("unexpected %q", c))
+	}
+	p.pos++
+	switch c {
+	case '-':
+		c = '−'
+	case '*':
+		c = '∗'
+	}
+	return "<mo>" + template.HTMLEscapeString(string(c)) + "</mo>", false, nil
+}
+
+// group parses the content between braces.
+func (p *mathParser) group() (string, error) {
+	p.skipSpaces()
+	if p.finished() || p.runes[p.pos] != '{' {
+		// a single atom can be used as argument
+		atom, _, err := p.atom()
+		return atom, err
+	}
+	p.pos++
+	content, err := p.expr()
+	if err != nil {
+		return "", err
+	}
+	if p.finished() || p.runes[p.pos] != '}' {
+		return "", errors.Join(ErrInvalidMath, errors.New("unclosed group"))
+	}
+	p.pos++
+	return content, nil
+}
+
+// rawGroup returns the text between braces.
+func (p *mathParser) rawGroup() (string, error) {
+	p.skipSpaces()
+	if p.finished() || p.runes[p.pos] != '{' {
+		return "", errors.Join(ErrInvalidMath, errors.New("missing group"))
+	}
+	depth := 0
+	for i := p.pos; i < len(p.runes); i++ {
+		switch p.runes[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				s := string(p.runes[p.pos+1 : i])
+				p.pos = i + 1
+				return s, nil
+			}
+		}
+	}
+	return "", errors.Join(ErrInvalidMath, errors.New("unclosed group"))
+}
+
+func (p *mathParser) commandAtom() (string, bool, error) {
+	name := p.command()
+	if s, ok := mathIdentifiers[name]; ok {
+		return "<mi>" + s + "</mi>", false, nil
+	}
+	if s, ok := mathUprights[name]; ok {
+		return `<mi mathvariant="normal">` + s + "</mi>", false, nil
+	}
+	if s, ok := mathOperators[name]; ok {
+		return "<mo>" + template.HTMLEscapeString(s) + "</mo>", false, nil
+	}
+	if op, ok := mathLargeOperators[name]; ok {
+		return "<mo>" + op.symbol + "</mo>", op.limits, nil
+	}
+	if limits, ok := mathFunctions[name]; ok {
+		return "<mi>" + name + "</mi>", limits, nil
+	}
+	if w, ok := mathSpaces[name]; ok {
+		return `<mspace width="` + w + `"></mspace>`, false, nil
+	}
+	if accent, ok := mathAccents[name]; ok {
+		content, err := p.group()
+		if err != nil {
+			return "", false, err
+		}
+		return `<mover accent="true"><mrow>` + content + "</mrow><mo>" + accent + "</mo></mover>", false, nil
+	}
+	switch name {
+	case "!":
+		// negative spaces are not supported by MathML Core
+		return "", false, nil
+	case "frac", "dfrac", "tfrac", "binom":
+		num, err := p.group()
+		if err != nil {
+			return "", false, err
+		}
+		den, err := p.group()
+		if err != nil {
+			return "", false, err
+		}
+		if name == "binom" {
+			return `<mrow><mo>(</mo><mfrac linethickness="0"><mrow>` + num + "</mrow><mrow>" + den +
+				"</mrow></mfrac><mo>)</mo></mrow>", false, nil
+		}
+		return "<mfrac><mrow>" + num + "</mrow><mrow>" + den + "</mrow></mfrac>", false, nil
+	case "sqrt":
+		var index string
+		p.skipSpaces()
+		if !p.finished() && p.runes[p.pos] == '[' {
+			p.pos++
+			p.index = true
+			var err error
+			index, err = p.expr()
+			p.index = false
+			if err != nil {
+				return "", false, err
+			}
+			if p.finished() || p.runes[p.pos] != ']' {
+				return "", false, errors.Join(ErrInvalidMath, errors.New("unclosed root index"))
+			}
+			p.pos++
+		}
+		content, err := p.group()
+		if err != nil {
+			return "", false, err
+		}
+		if len(index) > 0 {
+			return "<mroot><mrow>" + content + "</mrow><mrow>" + index + "</mrow></mroot>", false, nil
+		}
+		return "<msqrt>" + content + "</msqrt>", false, nil
+	case "text", "textrm", "mbox":
+		s, err := p.rawGroup()
+		return "<mtext>" + template.HTMLEscapeString(s) + "</mtext>", false, err
+	case "mathrm", "operatorname":
+		s, err := p.rawGroup()
+		if len([]rune(s)) == 1 {
+			return `<mi mathvariant="normal">` + template.HTMLEscapeString(s) + "</mi>", false, err
+		}
+		return "<mi>" + template.HTMLEscapeString(s) + "</mi>", false, err
+	case "mathbb", "mathbf":
+		s, err := p.rawGroup()
+		return "<mi>" + template.HTMLEscapeString(mathAlphabet(name, s)) + "</mi>", false, err
+	case "left":
+		return p.fenced()
+	case "begin":
+		return p.environment()
+	}
+	return "", false, errors.Join(ErrUnknownMathCommand, fmt.Errorf("command: \\%s", name))
+}
+
+// delimiter parses the delimiter after \left or \right.
+func (p *mathParser) delimiter() (string, error) {
+	p.skipSpaces()
+	if p.finished() {
+		return "", errors.Join(ErrInvalidMath, errors.New("missing delimiter"))
+	}
+	c := p.runes[p.pos]
+	switch {
+	case c == '.':
+		p.pos++
+		return "", nil
+	case c == '\\':
+		name := p.command()
+		if s, ok := mathOperators[name]; ok {
+			return "<mo>" + template.HTMLEscapeString(s) + "</mo>", nil
+		}
+		return "", errors.Join(ErrInvalidMath, fmt.Errorf("invalid delimiter: \\%s", name))
+	case strings.ContainsRune("()[]|/", c):
+		p.pos++
+		return "<mo>" + string(c) + "</mo>", nil
+	}
+	return "", errors.Join(ErrInvalidMath, fmt.Errorf("invalid delimiter: %q", c))
+}
+
+func (p *mathParser) fenced() (string, bool, error) {
+	open, err := p.delimiter()
+	if err != nil {
+		return "", false, err
+	}
+	content, err := p.expr()
+	if err != nil {
+		return "", false, err
+	}
+	if p.peekCommand() != "right" {
+		return "", false, errors.Join(ErrInvalidMath, errors.New("missing \\right"))
+	}
+	p.command()
+	closing, err := p.delimiter()
+	if err != nil {
+		return "", false, err
+	}
+	return "<mrow>" + open + content + closing + "</mrow>", false, nil
+}
+
+func (p *mathParser) environment() (string, bool, error) {
+	name, err := p.rawGroup()
+	if err != nil {
+		return "", false, err
+	}
+	fences, ok := mathFences[name]
+	if !ok {
+		return "", false, errors.Join(ErrInvalidMath, fmt.Errorf("unknown environment: %s", name))
+	}
+	var sb strings.Builder
+	sb.WriteString("<mtable")
+	if name == "cases" || name == "aligned" {
+		sb.WriteString(` columnalign="left"`)
+	}
+	sb.WriteString("><mtr><mtd>")
+	for {
+		content, err := p.expr()
+		if err != nil {
+			return "", false, err
+		}
+		sb.WriteString(content)
+		if p.finished() {
+			return "", false, errors.Join(ErrInvalidMath, fmt.Errorf("unclosed environment: %s", name))
+		}
+		if p.runes[p.pos] == '&' {
+			p.pos++
+			sb.WriteString("</mtd><mtd>")
+			continue
+		}
+		switch p.peekCommand() {
+		case "\\":
+			p.command()
+			sb.WriteString("</mtd></mtr><mtr><mtd>")
+			continue
+		case "end":
+			p.command()
+			end, err := p.rawGroup()
+			if err != nil {
+				return "", false, err
+			}
+			if end != name {
+				return "", false, errors.Join(ErrInvalidMath, fmt.Errorf("environment %s closed by %s", name, end))
+			}
+		default:
+			return "", false, errors.Join(ErrInvalidMath, fmt.Errorf("unexpected %q", p.rest()))
+		}
+		break
+	}
+	sb.WriteString("</mtd></mtr></mtable>")
+	table := sb.String()
+	if len(fences[0]) > 0 {
+		table = "<mo>" + fences[0] + "</mo>" + table
+	}
+	if len(fences[1]) > 0 {
+		table += "<mo>" + fences[1] + "</mo>"
+	}
+	return "<mrow>" + table + "</mrow>", false, nil
+}
+
+// mathAlphabet converts the letters and the digits of s into the mathematical alphabet of the command.
+func mathAlphabet(command, s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		switch {
+		case command == "mathbb" && strings.ContainsRune("CHNPQRZ", c):
+			// they are defined before the mathematical alphanumeric symbols
+			sb.WriteRune(map[rune]rune{'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'}[c])
+		case command == "mathbb" && c >= 'A' && c <= 'Z':
+			sb.WriteRune(0x1D538 + c - 'A')
+		case command == "mathbb" && c >= 'a' && c <= 'z':
+			sb.WriteRune(0x1D552 + c - 'a')
+		case command == "mathbb" && c >= '0' && c <= '9':
+			sb.WriteRune(0x1D7D8 + c - '0')
+		case command == "mathbf" && c >= 'A' && c <= 'Z':
+			sb.WriteRune(0x1D400 + c - 'A')
+		case command == "mathbf" && c >= 'a' && c <= 'z':
+			sb.WriteRune(0x1D41A + c - 'a')
+		case command == "mathbf" && c >= '0' && c <= '9':
+			sb.WriteRune(0x1D7CE + c - '0')
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
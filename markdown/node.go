@@ -0,0 +1,253 @@
+package markdown
+
+import (
+	"html/template"
+	"strings"
+)
+
+type NodeKind string
+
+const (
+	KindDocument      NodeKind = "document"
+	KindParagraph     NodeKind = "paragraph"
+	KindText          NodeKind = "text"
+	KindBreak         NodeKind = "break"
+	KindReplace       NodeKind = "replace"
+	KindEmphasis      NodeKind = "emphasis"
+	KindStrong        NodeKind = "strong"
+	KindStrikethrough NodeKind = "strikethrough"
+	KindMark          NodeKind = "mark"
+	KindSuperscript   NodeKind = "superscript"
+	KindSubscript     NodeKind = "subscript"
+	KindCode          NodeKind = "code"
+	KindCodeBlock     NodeKind = "code_block"
+	KindHeading       NodeKind = "heading"
+	KindLink          NodeKind = "link"
+	KindImage         NodeKind = "image"
+	KindQuote         NodeKind = "quote"
+	KindCitation      NodeKind = "citation"
+	KindCallout       NodeKind = "callout"
+	KindList          NodeKind = "list"
+	KindListItem      NodeKind = "list_item"
+	KindTable         NodeKind = "table"
+	KindTableRow      NodeKind = "table_row"
+	KindTableCell     NodeKind = "table_cell"
+	KindFootnoteRef   NodeKind = "footnote_ref"
+	KindFootnote      NodeKind = "footnote"
+	KindHTML          NodeKind = "html"
+	KindHTMLBlock     NodeKind = "html_block"
+	KindThematicBreak NodeKind = "thematic_break"
+	KindMath          NodeKind = "math"
+	KindDirective     NodeKind = "directive"
+	KindWikiLink      NodeKind = "wiki_link"
+
+	KindDefinitionList        NodeKind = "definition_list"
+	KindDefinitionTerm        NodeKind = "definition_term"
+	KindDefinitionDescription NodeKind = "definition_description"
+)
+
+// Node is an element of the parsed markdown.
+type Node interface {
+	Kind() NodeKind
+	Children() []Node
+	// Attr returns the attribute of the node, like "href" for a link or "level" for a heading.
+	// It returns an empty string if the node does not have this attribute.
+	Attr(key string) string
+}
+
+// Visitor is called by Walk for each node.
+// If the returned Visitor is not nil, Walk visits each children of the node with it, followed by a call of
+// Visit(nil).
+type Visitor interface {
+	Visit(n Node) Visitor
+}
+
+// Walk traverses the tree in depth-first order.
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+	for _, c := range n.Children() {
+		Walk(v, c)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order, calling f for each node and with nil after the children.
+// If f returns false, children of the node are not visited.
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
+
+// Text returns the raw text contained in the node.
+// Blocks are separated by a new line.
+func Text(n Node) string {
+	var sb strings.Builder
+	writeText(&sb, n)
+	return strings.TrimSpace(sb.String())
+}
+
+func writeText(sb *strings.Builder, n Node) {
+	switch v := n.(type) {
+	case astLiteral:
+		sb.WriteString(string(v))
+	case astBreak:
+		sb.WriteString(" ")
+	case *astCode:
+		sb.WriteString(v.content)
+	case *astMath:
+		sb.WriteString(v.tex())
+	case *astWikiLink:
+		sb.WriteString(v.content(""))
+	}
+	for _, c := range n.Children() {
+		writeText(sb, c)
+	}
+	switch n.Kind() {
+	case KindText, KindBreak, KindReplace, KindEmphasis, KindStrong, KindStrikethrough, KindMark, KindSuperscript,
+		KindSubscript, KindCode, KindLink, KindFootnoteRef, KindHTML, KindWikiLink:
+	case KindMath:
+		if v := n.(*astMath); v.display {
+			sb.WriteString("\n")
+		}
+	case KindTableCell:
+		sb.WriteString(" ")
+	default:
+		if !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+	}
+}
+
+// Document is the root of a parsed markdown.
+type Document struct {
+	tree *tree
+	opt  *Option
+	// warnings of the parsing, nil in strict mode
+	warnings *warnings
+	// warnings of the last rendering, nil in strict mode
+	rendered *warnings
+	// outline of the last rendering in HTML
+	outline Outline
+}
+
+// ParseDocument parses the markdown without rendering it.
+func ParseDocument(s string, opt *Option) (*Document, *ParseError) {
+	if opt == nil {
+		opt = new(Option)
+	}
+	if opt.ImageSource == nil {
+		opt.ImageSource = func(s string) string { return s }
+	}
+	if opt.RenderLink == nil {
+		opt.RenderLink = RenderLink
+	}
+	doc := &Document{opt: opt}
+	if opt.Lenient {
+		doc.warnings = new(warnings)
+	}
+	lxs := lex(s, opt)
+	lxs.warnings = doc.warnings
+	var err *ParseError
+	doc.tree, err = ast(lxs)
+	if err != nil {
+		return nil, err.withFile(opt)
+	}
+	return doc, nil
+}
+
+// Warnings returns the errors recovered while parsing and during the last rendering of the Document.
+// It is always empty if Option.Lenient is false.
+func (d *Document) Warnings() []*ParseError {
+	var res []*ParseError
+	for _, w := range []*warnings{d.warnings, d.rendered} {
+		if w == nil {
+			continue
+		}
+		for _, err := range *w {
+			res = append(res, err.withFile(d.opt))
+		}
+	}
+	return res
+}
+
+// option returns the Option used to render the Document.
+func (d *Document) option() *Option {
+	o := *d.opt
+	o.warnings = d.rendered
+	o.typography = newTypography(o.Typography)
+	return &o
+}
+
+// HTML returns the HTML of the Document.
+func (d *Document) HTML() (template.HTML, *ParseError) {
+	if d.opt.Lenient {
+		d.rendered = new(warnings)
+	}
+	opt := d.option()
+	opt.outline = new(Outline)
+	res, err := d.tree.Eval(opt)
+	d.outline = *opt.outline
+	return res, err.withFile(d.opt)
+}
+
+// Outline returns the Outline of the Document, filled by HTML.
+func (d *Document) Outline() Outline {
+	return d.outline
+}
+
+// Render the Document with the Renderer of its Option.
+func (d *Document) Render() (string, *ParseError) {
+	r := d.opt.Renderer
+	if r == nil {
+		r = HTMLRenderer
+	}
+	if d.opt.Lenient {
+		d.rendered = new(warnings)
+	}
+	res, err := r.Render(d)
+	return res, err.withFile(d.opt)
+}
+
+func (d *Document) Kind() NodeKind {
+	return KindDocument
+}
+
+func (d *Document) Children() []Node {
+	return append(nodes(d.tree.blocks), nodes(d.tree.footnotes)...)
+}
+
+func (d *Document) Attr(string) string {
+	return ""
+}
+
+// nodes converts blocks into nodes.
+// Modifiers only grouping other modifiers are replaced by their children.
+func nodes[T Node](bs []T) []Node {
+	res := make([]Node, 0, len(bs))
+	for _, b := range bs {
+		if m, ok := any(b).(*astModifier); ok && m.super {
+			res = append(res, m.Children()...)
+			continue
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
+// literal returns the raw value of a block containing only text.
+func literal(b block) string {
+	if v, ok := b.(astLiteral); ok {
+		return string(v)
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package markdown
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func parseDocument(t *testing.T, s string) *Document {
+	doc, err := ParseDocument(s, nil)
+	if err != nil {
+		t.Fatal(err.Pretty())
+	}
+	return doc
+}
+
+func TestWalk(t *testing.T) {
+	doc := parseDocument(t, raw)
+	var links, images, headings []string
+	Inspect(doc, func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		switch n.Kind() {
+		case KindLink:
+			links = append(links, n.Attr("href"))
+		case KindImage:
+			images = append(images, n.Attr("src"))
+		case KindHeading:
+			headings = append(headings, n.Attr("level")+" "+Text(n))
+		}
+		return true
+	})
+	if !slices.Equal(links, []string{"https://now.anhgelus.world/"}) {
+		t.Errorf("invalid links, got %v", links)
+	}
+	if !slices.Equal(images, []string{"https://cdn.anhgelus.world/pfp.jpg"}) {
+		t.Errorf("invalid images, got %v", images)
+	}
+	if !slices.Equal(headings, []string{"1 Je suis un titre"}) {
+		t.Errorf("invalid headings, got %v", headings)
+	}
+}
+
+func TestText(t *testing.T) {
+	doc := parseDocument(t, "# Titre\nDu **gras** et du `code`.\n\n- un\n- deux")
+	expected := "Titre\nDu gras et du code.\nun\ndeux"
+	if got := Text(doc); got != expected {
+		t.Errorf("invalid text, got %q", got)
+	}
+	if n := len(strings.Fields(Text(doc))); n != 8 {
+		t.Errorf("invalid number of words, got %d", n)
+	}
+}
+
+func TestNodeChildren(t *testing.T) {
+	doc := parseDocument(t, "Les ***deux***\n\n| a | b |\n|---|--:|\n| c | d |")
+	p := doc.Children()[0]
+	if k := p.Children()[1].Kind(); k != KindStrong {
+		t.Errorf("invalid kind, got %s", k)
+	}
+	table := doc.Children()[1]
+	rows := table.Children()
+	if len(rows) != 2 || rows[0].Attr("header") != "true" {
+		t.Fatalf("invalid rows, got %v", rows)
+	}
+	if align := rows[1].Children()[1].Attr("align"); align != "right" {
+		t.Errorf("invalid align, got %s", align)
+	}
+}
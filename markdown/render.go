@@ -0,0 +1,354 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Renderer renders a Document.
+type Renderer interface {
+	Render(doc *Document) (string, *ParseError)
+}
+
+var (
+	// HTMLRenderer renders the Document to HTML, like Parse.
+	HTMLRenderer Renderer = htmlRenderer{}
+	// TextRenderer renders the Document to plain text.
+	TextRenderer Renderer = textRenderer{}
+	// GemtextRenderer renders the Document to Gemtext, the format used by Gemini capsules.
+	GemtextRenderer Renderer = textRenderer{gemtext: true}
+)
+
+type htmlRenderer struct{}
+
+func (htmlRenderer) Render(doc *Document) (string, *ParseError) {
+	res, err := doc.HTML()
+	return string(res), err
+}
+
+type textRenderer struct {
+	gemtext bool
+}
+
+func (r textRenderer) Render(doc *Document) (string, *ParseError) {
+	st := &textState{textRenderer: r, opt: doc.option(), defs: make(map[string]Node)}
+	children := doc.Children()
+	for _, c := range children {
+		if c.Kind() == KindFootnote {
+			st.defs[c.Attr("id")] = c
+		}
+	}
+	blocks, err := st.blocks(children)
+	if err != nil {
+		return "", err
+	}
+	notes := make([]string, 0, len(st.order))
+	// defining a footnote can reference another one
+	for i := 0; i < len(st.order); i++ {
+		content, err := st.inline(st.defs[st.order[i]].Children())
+		if err != nil {
+			return "", err
+		}
+		notes = append(notes, fmt.Sprintf("[%d] %s", i+1, strings.TrimSpace(content))+st.flushLinks())
+	}
+	if len(notes) > 0 {
+		blocks = append(blocks, strings.Join(notes, "\n"))
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+type textLink struct {
+	href  string
+	label string
+}
+
+// textState stores the state of a rendering in plain text or in Gemtext.
+type textState struct {
+	textRenderer
+	opt  *Option
+	defs map[string]Node
+	// order of the first reference of each footnote
+	order []string
+	// links waiting to be written after the current block, only used by Gemtext
+	links []textLink
+}
+
+func (s *textState) blocks(ns []Node) ([]string, *ParseError) {
+	res := make([]string, 0, len(ns))
+	for _, n := range ns {
+		if n.Kind() == KindFootnote {
+			continue
+		}
+		b, err := s.block(n)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 0 {
+			res = append(res, b)
+		}
+	}
+	return res, nil
+}
+
+func (s *textState) block(n Node) (string, *ParseError) {
+	switch n.Kind() {
+	case KindHeading:
+		content, err := s.inline(n.Children())
+		if err != nil {
+			return "", err
+		}
+		content = strings.TrimSpace(content)
+		if s.gemtext {
+			level, _ := strconv.Atoi(n.Attr("level"))
+			content = strings.Repeat("#", min(level, 3)) + " " + content
+		}
+		return content + s.flushLinks(), nil
+	case KindCodeBlock:
+		content := strings.TrimSuffix(n.(*astCode).content, "\n")
+		if s.gemtext {
+			return "```" + n.Attr("lang") + "\n" + content + "\n```", nil
+		}
+		return content, nil
+	case KindImage:
+		caption, err := s.lines(n.Children(), "")
+		if err != nil {
+			return "", err
+		}
+		img := n.Attr("alt")
+		if s.gemtext {
+			img = "=> " + s.opt.ImageSource(n.Attr("src")) + " " + img
+		}
+		if len(caption) > 0 {
+			img += "\n" + caption
+		}
+		return img + s.flushLinks(), nil
+	case KindQuote, KindCallout:
+		return s.quote(n)
+	case KindList:
+		return s.list(n)
+	case KindTable:
+		return s.table(n)
+	case KindDefinitionList:
+		return s.definitions(n)
+	case KindThematicBreak:
+		return "---", nil
+	case KindMath:
+		return n.(*astMath).source, nil
+	case KindDirective:
+		blocks, err := s.blocks(n.Children())
+		return strings.Join(blocks, "\n\n"), err
+	default:
+		content, err := s.inline(n.Children())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(content) + s.flushLinks(), nil
+	}
+}
+
+// lines renders each node on its own line, starting with prefix.
+func (s *textState) lines(ns []Node, prefix string) (string, *ParseError) {
+	res := make([]string, 0, len(ns))
+	for _, n := range ns {
+		content, err := s.inline(n.Children())
+		if err != nil {
+			return "", err
+		}
+		content = strings.TrimSpace(content)
+		if len(content) > 0 {
+			res = append(res, prefix+content)
+		}
+	}
+	return strings.Join(res, "\n"), nil
+}
+
+func (s *textState) quote(n Node) (string, *ParseError) {
+	children := n.Children()
+	var res []string
+	if n.Kind() == KindCallout {
+		title, err := s.inline(children[0].Children())
+		if err != nil {
+			return "", err
+		}
+		title = strings.TrimSpace(title)
+		if len(title) == 0 {
+			title = n.Attr("kind")
+		}
+		res = append(res, "> "+title)
+		children = children[1:]
+	}
+	for _, c := range children {
+		prefix := "> "
+		ns := []Node{c}
+		if c.Kind() == KindCitation {
+			prefix = ""
+			ns = c.Children()
+		}
+		l, err := s.lines(ns, prefix)
+		if err != nil {
+			return "", err
+		}
+		res = append(res, l)
+	}
+	return strings.Join(res, "\n") + s.flushLinks(), nil
+}
+
+func (s *textState) list(n Node) (string, *ParseError) {
+	start, _ := strconv.Atoi(n.Attr("start"))
+	var res []string
+	for i, item := range n.Children() {
+		marker := "- "
+		if s.gemtext {
+			marker = "* "
+		}
+		if n.Attr("type") == string(listOrdered) {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+		blocks, err := s.blocks(item.Children())
+		if err != nil {
+			return "", err
+		}
+		for j, l := range strings.Split(strings.Join(blocks, "\n"), "\n") {
+			switch {
+			case j == 0:
+				l = marker + l
+			// Gemtext does not support nested lists
+			case !s.gemtext:
+				l = strings.Repeat(" ", len(marker)) + l
+			}
+			res = append(res, l)
+		}
+	}
+	return strings.Join(res, "\n"), nil
+}
+
+func (s *textState) definitions(n Node) (string, *ParseError) {
+	var res []string
+	for _, c := range n.Children() {
+		content, err := s.inline(c.Children())
+		if err != nil {
+			return "", err
+		}
+		content = strings.TrimSpace(content)
+		if c.Kind() == KindDefinitionDescription {
+			if s.gemtext {
+				content = "* " + content
+			} else {
+				content = "  " + content
+			}
+		}
+		res = append(res, content)
+	}
+	return strings.Join(res, "\n") + s.flushLinks(), nil
+}
+
+func (s *textState) table(n Node) (string, *ParseError) {
+	var res []string
+	for _, row := range n.Children() {
+		cells := make([]string, 0, len(row.Children()))
+		for _, c := range row.Children() {
+			content, err := s.inline(c.Children())
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, strings.TrimSpace(content))
+		}
+		res = append(res, strings.Join(cells, " | "))
+	}
+	content := strings.Join(res, "\n")
+	if s.gemtext {
+		content = "```\n" + content + "\n```"
+	}
+	return content + s.flushLinks(), nil
+}
+
+func (s *textState) inline(ns []Node) (string, *ParseError) {
+	var sb strings.Builder
+	for _, n := range ns {
+		switch n.Kind() {
+		case KindText:
+			sb.WriteString(literal(n.(block)))
+		case KindBreak:
+			if s.opt.Poem || len(n.Attr("hard")) > 0 {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString(" ")
+			}
+		case KindReplace:
+			sb.WriteString(html.UnescapeString(n.Attr("replace")))
+		case KindCode:
+			sb.WriteString(n.(*astCode).content)
+		case KindMath:
+			sb.WriteString(n.(*astMath).source)
+		case KindWikiLink:
+			wl := n.(*astWikiLink)
+			href, title, ok := wl.resolve(s.opt)
+			sb.WriteString(wl.content(title))
+			if ok && s.gemtext {
+				s.links = append(s.links, textLink{href: href, label: wl.content(title)})
+			}
+		case KindLink:
+			label, err := s.inline(n.Children())
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(label)
+			if s.gemtext {
+				s.links = append(s.links, textLink{href: n.Attr("href"), label: label})
+			}
+		case KindFootnoteRef:
+			i, err := s.footnote(n)
+			if err != nil {
+				return "", err
+			}
+			if i == 0 {
+				// unknown footnote recovered
+				sb.WriteString("[^" + n.Attr("id") + "]")
+			} else {
+				fmt.Fprintf(&sb, "[%d]", i)
+			}
+		default:
+			content, err := s.inline(n.Children())
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(content)
+		}
+	}
+	return sb.String(), nil
+}
+
+// footnote returns the number of the footnote referenced.
+func (s *textState) footnote(n Node) (int, *ParseError) {
+	id := n.Attr("id")
+	if s.defs[id] == nil {
+		err := &ParseError{
+			lxs:      n.(*astFootnoteRef).lxs,
+			internal: errors.Join(ErrUnknownFootnote, fmt.Errorf("footnote: %s", id)),
+		}
+		if !s.opt.warnings.recover(err) {
+			return 0, err
+		}
+		return 0, nil
+	}
+	for i, v := range s.order {
+		if v == id {
+			return i + 1, nil
+		}
+	}
+	s.order = append(s.order, id)
+	return len(s.order), nil
+}
+
+// flushLinks returns the Gemtext lines of the links used in the current block.
+func (s *textState) flushLinks() string {
+	var sb strings.Builder
+	for _, l := range s.links {
+		sb.WriteString("\n=> " + l.href + " " + strings.TrimSpace(l.label))
+	}
+	s.links = s.links[:0]
+	return sb.String()
+}
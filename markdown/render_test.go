@@ -0,0 +1,112 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+var rawRender = `
+# Titre
+Du **gras** et un [lien](https://example.org)[^1].
+
+- un
+  - deux
+1. trois
+
+> Citation
+source
+
+` + "```go\nfunc main() {}\n```" + `
+
+![Image](/pfp.jpg)
+
+Terme
+: définition
+
+---
+
+[^1]: Une note.
+`
+
+var expectedText = `
+Titre
+
+Du gras et un lien[1].
+
+- un
+  - deux
+
+1. trois
+
+> Citation
+source
+
+func main() {}
+
+Image
+
+Terme
+  définition
+
+---
+
+[1] Une note.
+`
+
+var expectedGemtext = `
+# Titre
+
+Du gras et un lien[1].
+=> https://example.org lien
+
+* un
+* deux
+
+1. trois
+
+> Citation
+source
+
+` + "```go\nfunc main() {}\n```" + `
+
+=> /static/pfp.jpg Image
+
+Terme
+* définition
+
+---
+
+[1] Une note.
+`
+
+func testRender(r Renderer, expected string) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+		opt := &Option{Renderer: r, ImageSource: func(s string) string { return "/static" + s }}
+		res, err := Render(rawRender, opt)
+		if err != nil {
+			t.Fatal(err.Pretty())
+		}
+		expected = strings.TrimPrefix(expected, "\n")
+		if res != expected {
+			t.Errorf("invalid value, got\n%s", res)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("render", func(t *testing.T) {
+		t.Run("text", testRender(TextRenderer, expectedText))
+		t.Run("gemtext", testRender(GemtextRenderer, expectedGemtext))
+		t.Run("html", func(t *testing.T) {
+			t.Parallel()
+			res, err := Render("Hello *world*", &Option{Renderer: HTMLRenderer})
+			if err != nil {
+				t.Fatal(err.Pretty())
+			}
+			if res != "<p>Hello <em>world</em></p>" {
+				t.Errorf("invalid value, got %s", res)
+			}
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Replacer substitutes a text by HTML.
+type Replacer struct {
+	// Symbol is the replaced text.
+	Symbol string
+	// Pattern matches the replaced text, it is used instead of Symbol if it is not nil.
+	// Replace can contain its submatches, like $1 (see regexp.Regexp.Expand).
+	Pattern *regexp.Regexp
+	// Replace is the HTML replacing the text.
+	Replace string
+}
+
+type replacement struct {
+	length  int
+	replace string
+}
+
+// markupLines are the lines never modified by the replacers, because they only contain markup: thematic breaks,
+// table delimiters and fences.
+var markupLines = regexp.MustCompile("(?m)^[ \t]*(?:(?:[-*_][ \t]*){3,}|\\|?(?:[ \t]*:?-+:?[ \t]*\\|)*[ \t]*:?-+:?[ \t]*\\|?|`{3}.*|:{3}.*)$")
+
+// replacements returns the replacements of the text, by position of their first rune.
+// Replacers are tried in order, and replacements cannot overlap.
+func replacements(s string, replacers []Replacer) map[int]replacement {
+	if len(replacers) == 0 {
+		return nil
+	}
+	// position of the rune starting at each byte
+	pos := make([]int, len(s)+1)
+	n := 0
+	for i := range s {
+		pos[i] = n
+		n++
+	}
+	pos[len(s)] = n
+	used := make([]bool, n)
+	for _, loc := range markupLines.FindAllStringIndex(s, -1) {
+		for i := pos[loc[0]]; i < pos[loc[1]]; i++ {
+			used[i] = true
+		}
+	}
+	res := make(map[int]replacement)
+	for _, r := range replacers {
+		re := r.Pattern
+		if re == nil {
+			if len(r.Symbol) == 0 {
+				continue
+			}
+			re = regexp.MustCompile(regexp.QuoteMeta(r.Symbol))
+		}
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := pos[loc[0]], pos[loc[1]]
+			if start == end || slices.Contains(used[start:end], true) {
+				continue
+			}
+			for i := start; i < end; i++ {
+				used[i] = true
+			}
+			rep := r.Replace
+			if r.Pattern != nil {
+				rep = string(re.ExpandString(nil, r.Replace, s, loc))
+			}
+			res[start] = replacement{length: end - start, replace: rep}
+		}
+	}
+	return res
+}
+
+// replaceable returns false if the rune at i starts a markup having the precedence over the replacers: modifiers, code
+// and block markers.
+func replaceable(runes []rune, i int, lineStart bool, closing map[int]bool) bool {
+	c := runes[i]
+	switch {
+	case c == '*' || c == '_' || c == '`':
+		return false
+	case c == '~' || c == '=' || c == '^':
+		n, _ := delimitedModifier(runes, i)
+		return n == 0 && !closing[i]
+	case lineStart && c == '#':
+		return false
+	case lineStart && strings.ContainsRune("->+:", c):
+		return i+1 < len(runes) && runes[i+1] != ' '
+	}
+	return true
+}
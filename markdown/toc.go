@@ -0,0 +1,110 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"anhgelus.world/small-web/dom"
+)
+
+var regexTag = regexp.MustCompile(`<[^>]*>`)
+
+var accents = map[rune]string{
+	'à': "a", 'â': "a", 'ä': "a", 'á': "a", 'ã': "a", 'å': "a",
+	'ç': "c",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e",
+	'î': "i", 'ï': "i", 'í': "i", 'ì': "i",
+	'ô': "o", 'ö': "o", 'ó': "o", 'ò': "o", 'õ': "o",
+	'ù': "u", 'û': "u", 'ü': "u", 'ú': "u",
+	'ÿ': "y", 'ý': "y",
+	'ñ': "n",
+	'œ': "oe", 'æ': "ae", 'ß': "ss",
+}
+
+// Heading is an entry of the Outline.
+type Heading struct {
+	Level uint
+	ID    string
+	Title string
+}
+
+// Outline is the list of headings of a document, in order of appearance.
+type Outline []*Heading
+
+func (o *Outline) add(level uint, content template.HTML) *Heading {
+	title := html.UnescapeString(regexTag.ReplaceAllString(string(content), ""))
+	h := &Heading{Level: level, Title: strings.TrimSpace(title)}
+	base := Slugify(h.Title)
+	if len(base) == 0 {
+		base = "section"
+	}
+	h.ID = base
+	for n := 2; o.has(h.ID); n++ {
+		h.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+	*o = append(*o, h)
+	return h
+}
+
+func (o Outline) has(id string) bool {
+	for _, h := range o {
+		if h.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Render the Outline as a table of contents.
+func (o Outline) Render() template.HTML {
+	if len(o) == 0 {
+		return ""
+	}
+	nav := dom.NewContentElement("nav", []dom.Element{o.list()})
+	nav.ClassList().Add("toc")
+	return nav.Render()
+}
+
+func (o Outline) list() dom.Element {
+	list := dom.NewContentElement("ol", make([]dom.Element, 0))
+	for i := 0; i < len(o); {
+		h := o[i]
+		j := i + 1
+		for j < len(o) && o[j].Level > h.Level {
+			j++
+		}
+		anchor := dom.NewLiteralContentElement("a", template.HTML(template.HTMLEscapeString(h.Title)))
+		anchor.SetAttribute("href", "#"+h.ID)
+		item := dom.NewContentElement("li", []dom.Element{anchor})
+		if j > i+1 {
+			item.Contents = append(item.Contents, o[i+1:j].list())
+		}
+		list.Contents = append(list.Contents, item)
+		i = j
+	}
+	return list
+}
+
+// Slugify returns a slug usable as an HTML id or in an URL.
+// Accents are folded.
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		if v, ok := accents[c]; ok {
+			sb.WriteString(v)
+			dash = false
+		} else if c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			sb.WriteRune(c)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
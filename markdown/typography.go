@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+const (
+	nbsp  = '\u00a0'
+	nnbsp = '\u202f'
+)
+
+// openings are the runes after which a quote opens a quotation.
+const openings = "([{«“‘—–-/"
+
+// typography replaces the ASCII punctuation of the text by the typographic one of a language.
+// Code, math and raw HTML are never modified.
+type typography struct {
+	french bool
+	// prev is the last rune written, 0 at the start of a block
+	prev rune
+}
+
+// newTypography returns the typography of the language, or nil if it is not supported.
+func newTypography(lang string) *typography {
+	base, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(lang, "_", "-")), "-")
+	switch base {
+	case "en":
+		return &typography{}
+	case "fr":
+		return &typography{french: true}
+	}
+	return nil
+}
+
+// reset is called at the start of a block.
+func (t *typography) reset() {
+	if t != nil {
+		t.prev = 0
+	}
+}
+
+// skip is called after a node which is not a literal.
+func (t *typography) skip(b block) {
+	if t == nil {
+		return
+	}
+	switch b.Kind() {
+	case KindBreak, KindReplace:
+		t.prev = ' '
+	case KindCode, KindMath, KindImage, KindFootnoteRef, KindWikiLink, KindDirective:
+		// like a word
+		t.prev = 'a'
+	}
+}
+
+func (t *typography) opening() bool {
+	return t.prev == 0 || unicode.IsSpace(t.prev) || strings.ContainsRune(openings, t.prev)
+}
+
+func (t *typography) apply(s string) string {
+	runes := []rune(s)
+	res := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '.' && strings.HasPrefix(string(runes[i:]), "..."):
+			res = append(res, '…')
+			i += 2
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			if i+2 < len(runes) && runes[i+2] == '-' {
+				res = append(res, '—')
+				i += 2
+			} else {
+				res = append(res, '–')
+				i++
+			}
+		case c == '\'':
+			if t.opening() && !elision(runes[i+1:]) {
+				res = append(res, '‘')
+			} else {
+				res = append(res, '’')
+			}
+		case c == '"' && !t.french:
+			if t.opening() {
+				res = append(res, '“')
+			} else {
+				res = append(res, '”')
+			}
+		case t.french && ((c == '"' && t.opening()) || c == '«'):
+			res = append(res, '«', nnbsp)
+			for i+1 < len(runes) && unicode.IsSpace(runes[i+1]) && runes[i+1] != '\n' {
+				i++
+			}
+		case t.french && (c == '"' || c == '»'):
+			res = append(trimSpaces(res), nnbsp, '»')
+		case t.french && strings.ContainsRune(":;!?", c):
+			space := nnbsp
+			if c == ':' {
+				space = nbsp
+			}
+			if len(res) > 0 && res[len(res)-1] == ' ' {
+				res[len(res)-1] = space
+			} else if t.missingSpace(runes[i+1:]) {
+				res = append(res, space)
+			}
+			res = append(res, c)
+		default:
+			res = append(res, c)
+		}
+		t.prev = res[len(res)-1]
+	}
+	return string(res)
+}
+
+// missingSpace returns true if a space must be inserted before the punctuation followed by next.
+// The punctuation must end a word, so URLs and times like 12:30 are not modified.
+func (t *typography) missingSpace(next []rune) bool {
+	if t.prev == 0 || unicode.IsSpace(t.prev) || strings.ContainsRune(":;!?", t.prev) || strings.ContainsRune(openings, t.prev) {
+		return false
+	}
+	return len(next) == 0 || unicode.IsSpace(next[0]) || strings.ContainsRune(":;!?)]}»\"", next[0])
+}
+
+// elision returns true if the quote at the start of a word followed by next is an apostrophe, like in 'em or '90s.
+// It is a quote if it is closed in next before another quote is opened.
+func elision(next []rune) bool {
+	if len(next) > 0 && unicode.IsDigit(next[0]) {
+		return true
+	}
+	for j := 1; j < len(next); j++ {
+		if next[j] != '\'' {
+			continue
+		}
+		if unicode.IsSpace(next[j-1]) {
+			return true
+		}
+		if j+1 == len(next) || !unicode.IsLetter(next[j+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func trimSpaces(runes []rune) []rune {
+	for len(runes) > 0 && unicode.IsSpace(runes[len(runes)-1]) && runes[len(runes)-1] != '\n' {
+		runes = runes[:len(runes)-1]
+	}
+	return runes
+}
+
+// evalInline evaluates the inline content, applying the typography to the consecutive literals.
+// The raw HTML elements opened in the content are closed at its end.
+func evalInline(content []block, opt *Option) (template.HTML, *ParseError) {
+	var res template.HTML
+	scope := opt.html.enter()
+	var run strings.Builder
+	flush := func() {
+		if run.Len() > 0 {
+			res += template.HTML(template.HTMLEscapeString(opt.typography.apply(run.String())))
+			run.Reset()
+		}
+	}
+	for _, c := range content {
+		if l, ok := c.(astLiteral); ok && opt.typography != nil {
+			run.WriteString(string(l))
+			continue
+		}
+		flush()
+		ct, err := c.Eval(opt)
+		if err != nil {
+			return "", err
+		}
+		res += ct
+		opt.typography.skip(c)
+	}
+	flush()
+	ct, err := opt.html.leave(scope, opt)
+	if err != nil {
+		return "", err
+	}
+	return res + ct, nil
+}
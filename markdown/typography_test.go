@@ -0,0 +1,36 @@
+package markdown
+
+import "testing"
+
+func TestTypography(t *testing.T) {
+	en := &Option{Typography: "en-US"}
+	fr := &Option{Typography: "fr"}
+	t.Run("english", func(t *testing.T) {
+		t.Run("quotes", testWithOptions(en, `She said "it's *mine*"`, "<p>She said “it’s <em>mine</em>”</p>"))
+		t.Run("single", testWithOptions(en, `a 'quote'`, "<p>a ‘quote’</p>"))
+		t.Run("ellipsis", testWithOptions(en, `wait...`, "<p>wait…</p>"))
+		t.Run("dashes", testWithOptions(en, `1--2 --- end`, "<p>1–2 — end</p>"))
+		t.Run("no french", testWithOptions(en, `Quoi ?`, "<p>Quoi ?</p>"))
+		t.Run("elision", testWithOptions(en, `'em in the '90s`, "<p>’em in the ’90s</p>"))
+		t.Run("elision quote", testWithOptions(en, `'em and 'quote'`, "<p>’em and ‘quote’</p>"))
+	})
+	t.Run("french", func(t *testing.T) {
+		t.Run("quotes", testWithOptions(fr, `Il dit "bonjour"`, "<p>Il dit « bonjour »</p>"))
+		t.Run("guillemets", testWithOptions(fr, `« bonjour »`, "<p>« bonjour »</p>"))
+		t.Run("emphasis", testWithOptions(fr, `"*bonjour*"`, "<p>« <em>bonjour</em> »</p>"))
+		t.Run("punctuation", testWithOptions(fr, `Quoi ! Vraiment ? Oui ; voilà : fin`,
+			"<p>Quoi ! Vraiment ? Oui ; voilà : fin</p>"))
+		t.Run("missing spaces", testWithOptions(fr, `Bonjour! Quoi?! Oui; voilà: fin.`,
+			"<p>Bonjour\u202f! Quoi\u202f?! Oui\u202f; voilà\u00a0: fin.</p>"))
+		t.Run("no spaces", testWithOptions(fr, `12:30 https://example.org/?a=b`,
+			"<p>12:30 https://example.org/?a=b</p>"))
+		t.Run("guillemets without spaces", testWithOptions(fr, `«Bonjour»`, "<p>«\u202fBonjour\u202f»</p>"))
+		t.Run("apostrophe", testWithOptions(fr, `l'arbre`, "<p>l’arbre</p>"))
+	})
+	t.Run("code", func(t *testing.T) {
+		t.Run("span", testWithOptions(fr, "`\"a\" -- b ?` ...", "<p><code>&#34;a&#34; -- b ?</code> …</p>"))
+		t.Run("block", testWithOptions(en, "```\n\"a\"...\n```", "<pre><code>&#34;a&#34;...\n</code></pre>"))
+	})
+	t.Run("link", testWithOptions(en, `["quote"](/a--b)`, `<p><a href="/a--b">“quote”</a></p>`))
+	t.Run("disabled", testWithOptions(nil, `"a" -- b...`, "<p>&#34;a&#34; -- b...</p>"))
+}